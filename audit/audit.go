@@ -0,0 +1,104 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const contextKey = "auditEntry"
+
+// Entry is the audit record of the current request. The audit middleware fills it
+// from the route and handlers refine it with the functions of this package.
+type Entry struct {
+	Action     string
+	EntityType string
+	EntityID   string
+	Details    map[string]interface{}
+	Subject    string // Email of the account an unauthenticated request acts on
+}
+
+// Begin attaches an entry derived from the matched route to the request. A
+// request to "/api/v1/product/categories/:id" with PATCH is recorded as
+// "product.categories.update" on entity "categories" with the value of the first
+// path parameter as ID. Routes without parameters use their first segment as entity.
+func Begin(c *gin.Context, prefix string) *Entry {
+	entry := &Entry{Details: map[string]interface{}{}}
+
+	segments := []string{}
+	for _, segment := range strings.Split(strings.TrimPrefix(c.FullPath(), prefix), "/") {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			if entry.EntityID == "" && len(segments) > 0 {
+				entry.EntityType = segments[len(segments)-1]
+				entry.EntityID = c.Param(segment[1:])
+			}
+			continue
+		}
+		segments = append(segments, segment)
+	}
+
+	if entry.EntityType == "" && len(segments) > 0 {
+		entry.EntityType = segments[0]
+	}
+	entry.Action = strings.Join(append(segments, verb(c.Request.Method)), ".")
+
+	c.Set(contextKey, entry)
+	return entry
+}
+
+// FromContext returns the entry of the request, nil outside the audit middleware
+func FromContext(c *gin.Context) *Entry {
+	value, ok := c.Get(contextKey)
+	if !ok {
+		return nil
+	}
+	entry, _ := value.(*Entry)
+	return entry
+}
+
+// SetAction replaces the action derived from the route
+func SetAction(c *gin.Context, action string) {
+	if entry := FromContext(c); entry != nil {
+		entry.Action = action
+	}
+}
+
+// SetEntity records the entity the request acted on, such as the ID of a row it created
+func SetEntity(c *gin.Context, entityType string, id interface{}) {
+	if entry := FromContext(c); entry != nil {
+		entry.EntityType = entityType
+		entry.EntityID = fmt.Sprint(id)
+	}
+}
+
+// SetSubject names the account of an unauthenticated request, such as the email
+// of a sign in, so the record is kept with that user if the account exists
+func SetSubject(c *gin.Context, email string) {
+	if entry := FromContext(c); entry != nil {
+		entry.Subject = email
+	}
+}
+
+// AddDetail stores a value with the record. Never pass secrets, details are kept in clear text.
+func AddDetail(c *gin.Context, key string, value interface{}) {
+	if entry := FromContext(c); entry != nil {
+		entry.Details[key] = value
+	}
+}
+
+func verb(method string) string {
+	switch method {
+	case "POST":
+		return "create"
+	case "PUT", "PATCH":
+		return "update"
+	case "DELETE":
+		return "delete"
+	default:
+		return strings.ToLower(method)
+	}
+}
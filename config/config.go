@@ -2,15 +2,175 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var JWTSecret = []byte(os.Getenv("JWT_SECRET"))
-
 type JWTClaims struct {
-	UserID    uint   `json:"user_id"`
-	Email     string `json:"email"`
-	TokenType string `json:"token_type"` // "access" or "refresh"
+	UserID         uint   `json:"user_id"`
+	Email          string `json:"email"`
+	TokenType      string `json:"token_type"`          // "access" or "refresh"
+	FamilyID       string `json:"family_id,omitempty"` // Shared by every refresh token of one login
+	TokenVersion   int    `json:"token_version"`       // Must match User.TokenVersion
+	OrganizationID uint   `json:"org_id,omitempty"`    // Active organization of access tokens, X-Organization-ID overrides it
 	jwt.RegisteredClaims
 }
+
+// AccessTokenTTL is the lifetime of bearer tokens accepted by AuthMiddleware
+func AccessTokenTTL() time.Duration {
+	return getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute)
+}
+
+// RefreshTokenTTL is the lifetime of refresh tokens returned by SignIn
+func RefreshTokenTTL() time.Duration {
+	return getEnvDuration("REFRESH_TOKEN_TTL", 7*24*time.Hour)
+}
+
+// TokenCleanupInterval controls how often expired blacklist and refresh token rows are purged
+func TokenCleanupInterval() time.Duration {
+	return getEnvDuration("TOKEN_CLEANUP_INTERVAL", time.Hour)
+}
+
+// MFATokenTTL is how long a user has to complete the second sign in step
+func MFATokenTTL() time.Duration {
+	return getEnvDuration("MFA_TOKEN_TTL", 5*time.Minute)
+}
+
+// TOTPIssuer is the account issuer shown by authenticator apps
+func TOTPIssuer() string {
+	issuer := os.Getenv("TOTP_ISSUER")
+	if issuer == "" {
+		issuer = "AI Inventory"
+	}
+	return issuer
+}
+
+// EmailVerificationTTL is how long an email verification link stays valid
+func EmailVerificationTTL() time.Duration {
+	return getEnvDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour)
+}
+
+// EmailVerificationResendInterval is the minimum time between two verification emails to one user
+func EmailVerificationResendInterval() time.Duration {
+	return getEnvDuration("EMAIL_VERIFICATION_RESEND_INTERVAL", 2*time.Minute)
+}
+
+// PasswordResetTTL is how long a password reset link stays valid
+func PasswordResetTTL() time.Duration {
+	return getEnvDuration("PASSWORD_RESET_TTL", time.Hour)
+}
+
+// FrontendURL is the base URL used to build links sent by email
+func FrontendURL() string {
+	url := os.Getenv("FRONTEND_URL")
+	if url == "" {
+		url = "http://localhost:3000"
+	}
+	return strings.TrimRight(url, "/")
+}
+
+// LoginMaxFailedAttempts is the number of consecutive failed sign ins that locks an account
+func LoginMaxFailedAttempts() int {
+	return getEnvInt("LOGIN_MAX_FAILED_ATTEMPTS", 5)
+}
+
+// LoginFailureWindow is how long a failed sign in counts towards the lockout threshold
+func LoginFailureWindow() time.Duration {
+	return getEnvDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute)
+}
+
+// LoginLockoutDuration is how long a locked account stays locked before it unlocks itself
+func LoginLockoutDuration() time.Duration {
+	return getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute)
+}
+
+// ElevationMaxHours is the longest a temporary permission elevation can be requested for
+func ElevationMaxHours() int {
+	return getEnvInt("ELEVATION_MAX_HOURS", 72)
+}
+
+// ElevationExpiryInterval controls how often expired permission elevations are closed
+func ElevationExpiryInterval() time.Duration {
+	return getEnvDuration("ELEVATION_EXPIRY_INTERVAL", 5*time.Minute)
+}
+
+// OrganizationInvitationTTL is how long an invitation to join an organization can be accepted
+func OrganizationInvitationTTL() time.Duration {
+	return getEnvDuration("ORGANIZATION_INVITATION_TTL", 7*24*time.Hour)
+}
+
+// OrganizationExportDir is the directory organization export archives are written to
+func OrganizationExportDir() string {
+	dir := os.Getenv("ORGANIZATION_EXPORT_DIR")
+	if dir == "" {
+		dir = "exports"
+	}
+	return dir
+}
+
+// OIDCStateTTL is how long a user has to complete a single sign on at the identity provider
+func OIDCStateTTL() time.Duration {
+	return getEnvDuration("OIDC_STATE_TTL", 10*time.Minute)
+}
+
+// TrustedProxies lists the proxies, as IPs or CIDRs, whose X-Forwarded-For header is
+// believed. Client IPs feed rate limits and audit records, so none are trusted by default.
+func TrustedProxies() []string {
+	proxies := []string{}
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// SeedAdminEmail is the superuser created by the seed command, it owns the seeded rows
+func SeedAdminEmail() string {
+	email := os.Getenv("SEED_ADMIN_EMAIL")
+	if email == "" {
+		email = "admin@ainventory.local"
+	}
+	return strings.ToLower(email)
+}
+
+// SeedOrganizationName is the organization the seed command writes its business data to
+func SeedOrganizationName() string {
+	name := os.Getenv("SEED_ORGANIZATION_NAME")
+	if name == "" {
+		name = "Default Organization"
+	}
+	return name
+}
+
+// SeedAdminPassword is the initial password of the seeded superuser. When empty a
+// random one is generated and printed once.
+func SeedAdminPassword() string {
+	return os.Getenv("SEED_ADMIN_PASSWORD")
+}
+
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+
+	return value
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return fallback
+	}
+
+	return duration
+}
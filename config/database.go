@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/tenant"
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -33,6 +34,10 @@ func ConnectDB() {
 		log.Fatalf("Error connecting to the database: %v", dbErr)
 	}
 
+	if err := tenant.Register(db, models.TenantModels()...); err != nil {
+		log.Fatalf("Error registering organization scoping: %v", err)
+	}
+
 	DB = db
 	log.Println("Database connection established successfully")
 }
@@ -56,29 +61,48 @@ func MigrateDB() {
 		&models.DepartmentHistory{},
 		&models.User{},
 		&models.TokenBlacklist{},
+		&models.RefreshToken{},
+		&models.PasswordResetToken{},
+		&models.UserSession{},
+		&models.OIDCLoginState{},
+		&models.UserIdentity{},
+		&models.RateLimitBucket{},
+		&models.APIKey{},
 		&models.UserHistory{},
 		&models.UserPermission{},
+		&models.PermissionElevationRequest{},
 		&models.UserProfile{},
 		&models.Customer{},
 		&models.Organization{},
-		// &models.Product{},
-		// &models.ProductCategory{},
-		// &models.ProductImage{},
-		// &models.ProductVariant{},
-		// &models.InventoryTransaction{},
-		// &models.ProductPriceHistory{},
-		// &models.Supplier{},
-		// &models.ProductReview{},
-		// &models.Order{},
-		// &models.OrderItem{},
-		// &models.OrderHistory{},
-		// &models.OrderPayment{},
-		// &models.OrderShipment{},
-		// &models.OrderShipmentItem{},
+		&models.OrganizationMember{},
+		&models.OrganizationInvitation{},
+		&models.OrganizationSettings{},
+		&models.OrganizationExport{},
+		&models.ProductCategory{},
+		&models.Supplier{},
+		&models.Product{},
+		&models.ProductImage{},
+		&models.ProductVariant{},
+		&models.InventoryTransaction{},
+		&models.ProductPriceHistory{},
+		&models.ProductReview{},
+		&models.Order{},
+		&models.OrderItem{},
+		&models.OrderHistory{},
+		&models.OrderPayment{},
+		&models.OrderShipment{},
+		&models.OrderShipmentItem{},
 	}
 
 	//DB.Migrator().DropTable(&models.User{})
 
+	if err := models.PrepareTenantColumns(DB); err != nil {
+		log.Fatalf("Error preparing organization columns: %v", err)
+	}
+	if err := models.PrepareOrganizationPermissions(DB); err != nil {
+		log.Fatalf("Error moving permissions to organizations: %v", err)
+	}
+
 	for _, model := range dbModels {
 		err := DB.AutoMigrate(model)
 		if err != nil {
@@ -89,5 +113,9 @@ func MigrateDB() {
 
 	}
 
+	if err := models.BackfillOrganizationOwners(DB); err != nil {
+		log.Fatalf("Error backfilling organization owners: %v", err)
+	}
+
 	log.Println("Model migration completed!")
 }
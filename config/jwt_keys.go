@@ -0,0 +1,139 @@
+package config
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSAKeyBits is the smallest RSA modulus accepted for token keys
+const minRSAKeyBits = 2048
+
+// JWTKey is one key of the token key set. Retired keys only carry the public half,
+// they are kept so tokens they signed stay valid until they expire.
+type JWTKey struct {
+	ID      string // Published as the kid header and in the JWKS
+	Method  jwt.SigningMethod
+	Private crypto.Signer // nil for verification only keys
+	Public  crypto.PublicKey
+}
+
+// JWTKeySet holds the key that signs new tokens and every key tokens are verified with
+type JWTKeySet struct {
+	Signing *JWTKey
+	Keys    []*JWTKey
+}
+
+// JWTKeys is loaded by LoadJWTKeys at startup
+var JWTKeys *JWTKeySet
+
+// LoadJWTKeys reads every <kid>.pem file of JWT_KEYS_DIR. Files may hold an RSA
+// (RS256) or Ed25519 (EdDSA) private key, or only a public key for a retired one.
+// JWT_SIGNING_KEY_ID selects the signing key and may be left empty when the
+// directory holds a single private key.
+//
+// To rotate, add the new private key, point JWT_SIGNING_KEY_ID at it and replace
+// the old file with its public key. Remove it once REFRESH_TOKEN_TTL has passed.
+func LoadJWTKeys() error {
+	dir := os.Getenv("JWT_KEYS_DIR")
+	if dir == "" {
+		return errors.New("JWT_KEYS_DIR is not set, no token signing key is configured")
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	keySet := &JWTKeySet{}
+	signers := []*JWTKey{}
+	for _, path := range paths {
+		key, err := loadJWTKey(path)
+		if err != nil {
+			return fmt.Errorf("JWT key %s: %w", path, err)
+		}
+		keySet.Keys = append(keySet.Keys, key)
+		if key.Private != nil {
+			signers = append(signers, key)
+		}
+	}
+
+	signingKeyID := os.Getenv("JWT_SIGNING_KEY_ID")
+	switch {
+	case signingKeyID != "":
+		key, ok := keySet.Key(signingKeyID)
+		if !ok || key.Private == nil {
+			return fmt.Errorf("JWT_SIGNING_KEY_ID %q has no private key in %s", signingKeyID, dir)
+		}
+		keySet.Signing = key
+	case len(signers) == 1:
+		keySet.Signing = signers[0]
+	case len(signers) == 0:
+		return fmt.Errorf("no private key found in %s, generate one with: openssl genpkey -algorithm ed25519 -out %s", dir, filepath.Join(dir, "<kid>.pem"))
+	default:
+		return fmt.Errorf("%s holds %d private keys, set JWT_SIGNING_KEY_ID to pick the signing key", dir, len(signers))
+	}
+
+	JWTKeys = keySet
+	return nil
+}
+
+// Key returns the verification key with the given kid
+func (ks *JWTKeySet) Key(id string) (*JWTKey, bool) {
+	for _, key := range ks.Keys {
+		if key.ID == id {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+func loadJWTKey(path string) (*JWTKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &JWTKey{ID: strings.TrimSuffix(filepath.Base(path), ".pem")}
+
+	if rsaKey, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		key.Private = rsaKey
+	} else if edKey, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+		if signer, ok := edKey.(crypto.Signer); ok {
+			key.Private = signer
+		}
+	}
+
+	if key.Private != nil {
+		key.Public = key.Private.Public()
+	} else if rsaKey, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		key.Public = rsaKey
+	} else if edKey, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+		key.Public = edKey
+	} else {
+		return nil, errors.New("not an RSA or Ed25519 key in PEM format")
+	}
+
+	switch public := key.Public.(type) {
+	case *rsa.PublicKey:
+		if public.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA keys must be at least %d bits", minRSAKeyBits)
+		}
+		key.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, errors.New("only RSA and Ed25519 keys are supported")
+	}
+
+	return key, nil
+}
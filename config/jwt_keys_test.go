@@ -0,0 +1,265 @@
+package config
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeKey writes the private key, or with public set only its public half, as <kid>.pem
+func writeKey(t *testing.T, dir, kid string, key crypto.Signer, public bool) {
+	t.Helper()
+
+	block := &pem.Block{Type: "PRIVATE KEY"}
+	var err error
+	if public {
+		block.Type = "PUBLIC KEY"
+		block.Bytes, err = x509.MarshalPKIXPublicKey(key.Public())
+	} else {
+		block.Bytes, err = x509.MarshalPKCS8PrivateKey(key)
+	}
+	if err != nil {
+		t.Fatalf("encoding key %s: %v", kid, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatalf("writing key %s: %v", kid, err)
+	}
+}
+
+func newEd25519Key(t *testing.T) crypto.Signer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating Ed25519 key: %v", err)
+	}
+	return key
+}
+
+func newRSAKey(t *testing.T, bits int) crypto.Signer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatalf("generating RSA key: %v", err)
+	}
+	return key
+}
+
+// loadKeys points JWT_KEYS_DIR at dir and loads it, restoring JWTKeys afterwards
+func loadKeys(t *testing.T, dir, signingKeyID string) error {
+	t.Helper()
+
+	previous := JWTKeys
+	t.Cleanup(func() { JWTKeys = previous })
+	t.Setenv("JWT_KEYS_DIR", dir)
+	t.Setenv("JWT_SIGNING_KEY_ID", signingKeyID)
+	return LoadJWTKeys()
+}
+
+func TestLoadJWTKeysSelectsSigningKey(t *testing.T) {
+	edKey := newEd25519Key(t)
+	rsaKey := newRSAKey(t, 2048)
+
+	tests := []struct {
+		name         string
+		setup        func(dir string)
+		signingKeyID string
+		wantSigning  string
+		wantMethod   string
+		wantKeys     []string
+		wantErr      string
+	}{
+		{
+			name:        "single Ed25519 key",
+			setup:       func(dir string) { writeKey(t, dir, "ed", edKey, false) },
+			wantSigning: "ed",
+			wantMethod:  "EdDSA",
+			wantKeys:    []string{"ed"},
+		},
+		{
+			name:        "single RSA key",
+			setup:       func(dir string) { writeKey(t, dir, "rsa", rsaKey, false) },
+			wantSigning: "rsa",
+			wantMethod:  "RS256",
+			wantKeys:    []string{"rsa"},
+		},
+		{
+			name: "retired public key next to the signing key",
+			setup: func(dir string) {
+				writeKey(t, dir, "2026-01", rsaKey, true)
+				writeKey(t, dir, "2026-02", edKey, false)
+			},
+			wantSigning: "2026-02",
+			wantMethod:  "EdDSA",
+			wantKeys:    []string{"2026-01", "2026-02"},
+		},
+		{
+			name: "signing key picked among several",
+			setup: func(dir string) {
+				writeKey(t, dir, "2026-01", rsaKey, false)
+				writeKey(t, dir, "2026-02", edKey, false)
+			},
+			signingKeyID: "2026-01",
+			wantSigning:  "2026-01",
+			wantMethod:   "RS256",
+			wantKeys:     []string{"2026-01", "2026-02"},
+		},
+		{
+			name: "several private keys without a signing key",
+			setup: func(dir string) {
+				writeKey(t, dir, "2026-01", rsaKey, false)
+				writeKey(t, dir, "2026-02", edKey, false)
+			},
+			wantErr: "set JWT_SIGNING_KEY_ID",
+		},
+		{
+			name:         "signing key without its private half",
+			setup:        func(dir string) { writeKey(t, dir, "2026-01", edKey, true) },
+			signingKeyID: "2026-01",
+			wantErr:      "has no private key",
+		},
+		{
+			name:         "unknown signing key",
+			setup:        func(dir string) { writeKey(t, dir, "2026-01", edKey, false) },
+			signingKeyID: "2026-02",
+			wantErr:      "has no private key",
+		},
+		{
+			name:    "only public keys",
+			setup:   func(dir string) { writeKey(t, dir, "2026-01", edKey, true) },
+			wantErr: "no private key found",
+		},
+		{
+			name:    "short RSA key",
+			setup:   func(dir string) { writeKey(t, dir, "weak", newRSAKey(t, 1024), false) },
+			wantErr: "at least 2048 bits",
+		},
+		{
+			name: "not a key",
+			setup: func(dir string) {
+				os.WriteFile(filepath.Join(dir, "junk.pem"), []byte("not a key"), 0o600)
+			},
+			wantErr: "not an RSA or Ed25519 key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.setup(dir)
+
+			err := loadKeys(t, dir, tt.signingKeyID)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loading keys: %v", err)
+			}
+
+			if JWTKeys.Signing.ID != tt.wantSigning {
+				t.Errorf("signing key = %s, want %s", JWTKeys.Signing.ID, tt.wantSigning)
+			}
+			if JWTKeys.Signing.Method.Alg() != tt.wantMethod {
+				t.Errorf("signing method = %s, want %s", JWTKeys.Signing.Method.Alg(), tt.wantMethod)
+			}
+			ids := make([]string, 0, len(JWTKeys.Keys))
+			for _, key := range JWTKeys.Keys {
+				ids = append(ids, key.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.wantKeys, ",") {
+				t.Errorf("keys = %v, want %v", ids, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestLoadJWTKeysRequiresDirectory(t *testing.T) {
+	if err := loadKeys(t, "", ""); err == nil {
+		t.Fatal("loading without JWT_KEYS_DIR succeeded")
+	}
+}
+
+// TestJWTKeyRotation follows the documented rotation: tokens signed before it keep
+// verifying with the retired public key until that key is removed
+func TestJWTKeyRotation(t *testing.T) {
+	dir := t.TempDir()
+	oldKey := newRSAKey(t, 2048)
+	newKey := newEd25519Key(t)
+
+	sign := func() string {
+		t.Helper()
+		token := jwt.NewWithClaims(JWTKeys.Signing.Method, jwt.RegisteredClaims{Subject: "1"})
+		token.Header["kid"] = JWTKeys.Signing.ID
+		signed, err := token.SignedString(JWTKeys.Signing.Private)
+		if err != nil {
+			t.Fatalf("signing token: %v", err)
+		}
+		return signed
+	}
+	verify := func(signed string) error {
+		_, err := jwt.Parse(signed, func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			key, ok := JWTKeys.Key(kid)
+			if !ok {
+				return nil, jwt.ErrTokenUnverifiable
+			}
+			return key.Public, nil
+		}, jwt.WithValidMethods([]string{"RS256", "EdDSA"}))
+		return err
+	}
+
+	writeKey(t, dir, "2026-01", oldKey, false)
+	if err := loadKeys(t, dir, ""); err != nil {
+		t.Fatalf("loading the first key: %v", err)
+	}
+	oldToken := sign()
+
+	// Add the new key, sign with it and retire the old one
+	writeKey(t, dir, "2026-02", newKey, false)
+	writeKey(t, dir, "2026-01", oldKey, true)
+	if err := loadKeys(t, dir, "2026-02"); err != nil {
+		t.Fatalf("loading the rotated keys: %v", err)
+	}
+	if retired, _ := JWTKeys.Key("2026-01"); retired.Private != nil {
+		t.Error("the retired key can still sign")
+	}
+	newToken := sign()
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("parsing the new token: %v", err)
+	}
+	if parsed.Header["kid"] != "2026-02" || parsed.Method.Alg() != "EdDSA" {
+		t.Fatalf("new tokens are signed with %v/%s, want 2026-02/EdDSA", parsed.Header["kid"], parsed.Method.Alg())
+	}
+	if err := verify(oldToken); err != nil {
+		t.Errorf("token of the retired key no longer verifies: %v", err)
+	}
+	if err := verify(newToken); err != nil {
+		t.Errorf("token of the new key does not verify: %v", err)
+	}
+
+	// Once the old tokens expired the retired key is removed
+	if err := os.Remove(filepath.Join(dir, "2026-01.pem")); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadKeys(t, dir, "2026-02"); err != nil {
+		t.Fatalf("loading after removing the retired key: %v", err)
+	}
+	if err := verify(oldToken); err == nil {
+		t.Error("token of a removed key still verifies")
+	}
+	if err := verify(newToken); err != nil {
+		t.Errorf("token of the new key does not verify: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/mapper"
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/tenant"
+	"github.com/farhapartex/ainventory/utils"
+	"gorm.io/gorm"
+)
+
+// CreateAPIKey creates a service account for the organization together with its key.
+// The full key is only part of this response, afterwards only its hash is known.
+func (ac *AuthController) CreateAPIKey(user *models.User, organizationID uint, req dto.APIKeyCreateRequestDTO, ipAddress string) (*dto.APIKeyCreateResponseDTO, error) {
+	organization, err := ac.findManagedOrganization(user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("expiry must be in the future")
+	}
+
+	var permissionCount int64
+	ac.DB.Model(&models.Permission{}).Where("name IN ? AND is_active = ?", req.Scopes, true).Count(&permissionCount)
+	if int(permissionCount) != len(uniqueStrings(req.Scopes)) {
+		return nil, errors.New("scopes must be names of active permissions")
+	}
+
+	// A key can never do more than the user who created it can in its organization
+	scoped := ac.DB.WithContext(tenant.WithOrganization(ac.DB.Statement.Context, organization.ID))
+	for _, scope := range req.Scopes {
+		if !user.IsSuperuser && !user.HasPermission(scoped, scope) {
+			return nil, fmt.Errorf("%w: you cannot grant the %s scope without holding it", ErrPrivilegeEscalation, scope)
+		}
+	}
+
+	key, prefix, err := utils.GenerateAPIKey()
+	if err != nil {
+		return nil, errors.New("failed to generate api key")
+	}
+
+	// Service accounts never sign in, the random password only satisfies the schema
+	password, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return nil, errors.New("failed to generate api key")
+	}
+
+	apiKey := models.APIKey{
+		OrganizationID: organization.ID,
+		Name:           req.Name,
+		Prefix:         prefix,
+		KeyHash:        utils.HashToken(key),
+		ExpiresAt:      req.ExpiresAt,
+		CreatedBy:      user.ID,
+	}
+	if err := apiKey.SetScopes(uniqueStrings(req.Scopes)); err != nil {
+		return nil, errors.New("failed to generate api key")
+	}
+
+	err = ac.DB.Transaction(func(tx *gorm.DB) error {
+		serviceAccount := models.User{
+			FirstName:        req.Name,
+			LastName:         "Service Account",
+			Email:            fmt.Sprintf("svc-%s@service-accounts.invalid", prefix),
+			Password:         password,
+			IsServiceAccount: true,
+			Status:           "active",
+			EmailVerified:    true,
+			CreatedBy:        &user.ID,
+		}
+		if err := tx.Create(&serviceAccount).Error; err != nil {
+			return err
+		}
+
+		apiKey.ServiceAccountID = serviceAccount.ID
+		if err := tx.Create(&apiKey).Error; err != nil {
+			return err
+		}
+
+		return user.AddHistory(tx, "API_KEY_CREATED", map[string]interface{}{
+			"api_key_id":      apiKey.ID,
+			"organization_id": organization.ID,
+			"prefix":          prefix,
+		}, ipAddress)
+	})
+	if err != nil {
+		return nil, errors.New("failed to create api key")
+	}
+
+	return &dto.APIKeyCreateResponseDTO{
+		APIKeyResponseDTO: mapper.APIKeyModelToDTO(apiKey),
+		Key:               key,
+	}, nil
+}
+
+func (ac *AuthController) APIKeyList(user *models.User, organizationID uint) ([]dto.APIKeyResponseDTO, error) {
+	organization, err := ac.findManagedOrganization(user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiKeys []models.APIKey
+	if err := ac.DB.Where("organization_id = ?", organization.ID).Order("created_at DESC").Find(&apiKeys).Error; err != nil {
+		return nil, errors.New("error retrieving api keys")
+	}
+
+	responseDTOs := make([]dto.APIKeyResponseDTO, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		responseDTOs = append(responseDTOs, mapper.APIKeyModelToDTO(apiKey))
+	}
+
+	return responseDTOs, nil
+}
+
+func (ac *AuthController) RevokeAPIKey(user *models.User, organizationID, apiKeyID uint, ipAddress string) error {
+	organization, err := ac.findManagedOrganization(user, organizationID)
+	if err != nil {
+		return err
+	}
+
+	var apiKey models.APIKey
+	result := ac.DB.Where("id = ? AND organization_id = ?", apiKeyID, organization.ID).First(&apiKey)
+	if result.RowsAffected == 0 {
+		return errors.New("api key not found")
+	}
+
+	if apiKey.RevokedAt != nil {
+		return nil
+	}
+
+	if err := apiKey.Revoke(ac.DB); err != nil {
+		return errors.New("failed to revoke api key")
+	}
+
+	user.AddHistory(ac.DB, "API_KEY_REVOKED", map[string]interface{}{
+		"api_key_id":      apiKey.ID,
+		"organization_id": organization.ID,
+		"prefix":          apiKey.Prefix,
+	}, ipAddress)
+
+	return nil
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
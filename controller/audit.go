@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/mapper"
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/tenant"
+)
+
+// AuditLogList returns audit records, newest first, for compliance reviews. Only
+// superusers see every record, everyone else the records of their active organization.
+func (ac *AuthController) AuditLogList(actor *models.User, query dto.AuditLogQueryDTO) (*dto.PaginatedResponse, error) {
+	db := ac.DB.Model(&models.UserHistory{})
+	if !actor.IsSuperuser {
+		organizationID, ok := tenant.OrganizationID(ac.DB.Statement.Context)
+		if !ok {
+			return nil, fmt.Errorf("%w: select an organization to review its audit records", ErrOrganizationAccess)
+		}
+		db = db.Where("organization_id = ?", organizationID)
+	}
+	if query.UserID != nil {
+		db = db.Where("user_id = ?", *query.UserID)
+	}
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+	if query.EntityType != "" {
+		db = db.Where("entity_type = ?", query.EntityType)
+	}
+	if query.EntityID != "" {
+		db = db.Where("entity_id = ?", query.EntityID)
+	}
+	if query.Outcome != "" {
+		db = db.Where("outcome = ?", query.Outcome)
+	}
+	if query.RequestID != "" {
+		db = db.Where("request_id = ?", query.RequestID)
+	}
+	if query.From != nil {
+		db = db.Where("created_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		db = db.Where("created_at < ?", *query.To)
+	}
+
+	var totalCount int64
+	if err := db.Count(&totalCount).Error; err != nil {
+		return nil, errors.New("error counting audit records")
+	}
+
+	var records []models.UserHistory
+	offset := (query.Page - 1) * query.PageSize
+	err := db.Order("created_at DESC, id DESC").Offset(offset).Limit(query.PageSize).Preload("User").Find(&records).Error
+	if err != nil {
+		return nil, errors.New("error retrieving audit records")
+	}
+
+	responseDTOs := []dto.AuditLogResponseDTO{}
+	for _, record := range records {
+		responseDTOs = append(responseDTOs, mapper.UserHistoryModelToDTO(record))
+	}
+
+	totalPages := (totalCount + int64(query.PageSize) - 1) / int64(query.PageSize)
+
+	return &dto.PaginatedResponse{
+		Data:       responseDTOs,
+		TotalPages: totalPages,
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		Total:      len(responseDTOs),
+	}, nil
+}
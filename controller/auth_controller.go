@@ -2,16 +2,25 @@ package controller
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"net/url"
 	"strings"
+	"time"
 
+	"github.com/farhapartex/ainventory/config"
 	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/mailer"
 	"github.com/farhapartex/ainventory/mapper"
 	"github.com/farhapartex/ainventory/models"
 	"github.com/farhapartex/ainventory/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-func (ac *AuthController) SignIn(req dto.SignInRequestDTO) (*dto.SignInResponseDTO, error) {
+var ErrAccountLocked = errors.New("Account is locked due to too many failed login attempts")
+
+func (ac *AuthController) SignIn(req dto.SignInRequestDTO, ipAddress, userAgent string) (*dto.SignInResponseDTO, error) {
 	req.Email = strings.ToLower((req.Email))
 	var user models.User
 	result := ac.DB.Where("email = ?", req.Email).First(&user)
@@ -23,24 +32,268 @@ func (ac *AuthController) SignIn(req dto.SignInRequestDTO) (*dto.SignInResponseD
 		return nil, errors.New("failed to retrieve user")
 	}
 
+	if user.AccountLocked {
+		if !user.IsLockExpired(config.LoginLockoutDuration()) {
+			return nil, ErrAccountLocked
+		}
+		if err := user.UnlockAccount(ac.DB); err != nil {
+			return nil, errors.New("failed to unlock account")
+		}
+	}
+
+	if user.CheckPassword(req.Password) != true {
+		user.IncrementFailedLoginAttempts(ac.DB, config.LoginMaxFailedAttempts(), config.LoginFailureWindow())
+		user.AddHistory(ac.DB, "LOGIN_FAILED", map[string]interface{}{
+			"failed_attempts": user.FailedLoginAttempts,
+		}, ipAddress)
+
+		if user.AccountLocked {
+			user.AddHistory(ac.DB, "ACCOUNT_LOCKED", map[string]interface{}{
+				"failed_attempts": user.FailedLoginAttempts,
+				"locked_until":    user.LockedAt.Add(config.LoginLockoutDuration()),
+			}, ipAddress)
+			return nil, ErrAccountLocked
+		}
+
+		return nil, errors.New("Invalid email or password")
+	}
+
 	if user.CanLogin() != true {
 		return nil, errors.New("Permission denied to login")
 	}
 
-	if user.CheckPassword(req.Password) != true {
-		return nil, errors.New("invalid password")
+	if user.TwoFactorEnabled {
+		mfaToken, err := utils.GenerateMFAToken(user)
+		if err != nil {
+			return nil, errors.New("failed to generate token")
+		}
+		return &dto.SignInResponseDTO{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}, nil
+	}
+
+	return ac.completeSignIn(&user, ipAddress, userAgent)
+
+}
+
+// completeSignIn records a successful login and starts a new session
+func (ac *AuthController) completeSignIn(user *models.User, ipAddress, userAgent string) (*dto.SignInResponseDTO, error) {
+	if err := user.RecordTokenIssue(ac.DB, ipAddress); err != nil {
+		return nil, errors.New("failed to record login")
+	}
+
+	return ac.startSession(ac.DB, *user, ipAddress, userAgent)
+}
+
+// startSession records a new session with its own refresh token family and issues its first token pair
+func (ac *AuthController) startSession(tx *gorm.DB, user models.User, ipAddress, userAgent string) (*dto.SignInResponseDTO, error) {
+	familyID, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	if len(userAgent) > 500 {
+		userAgent = userAgent[:500]
+	}
+
+	now := time.Now()
+	session := models.UserSession{
+		UserID:     user.ID,
+		FamilyID:   familyID,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		LastSeenIP: ipAddress,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(config.RefreshTokenTTL()),
+	}
+	if err := tx.Create(&session).Error; err != nil {
+		return nil, errors.New("failed to create session")
+	}
+
+	return ac.issueTokenPair(tx, user, familyID)
+}
+
+func (ac *AuthController) RefreshToken(req dto.RefreshTokenRequestDTO, ipAddress string) (*dto.SignInResponseDTO, error) {
+	claims, err := utils.ValidateJWT(req.RefreshToken)
+	if err != nil || claims.TokenType != "refresh" {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	var response *dto.SignInResponseDTO
+	reused := false
+
+	err = ac.DB.Transaction(func(tx *gorm.DB) error {
+		var stored models.RefreshToken
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", utils.HashToken(req.RefreshToken)).
+			First(&stored)
+		if result.Error != nil {
+			return errors.New("invalid refresh token")
+		}
+
+		if stored.UsedAt != nil || stored.RevokedAt != nil {
+			reused = stored.UsedAt != nil
+			return errors.New("invalid refresh token")
+		}
+
+		if !stored.IsUsable() {
+			return errors.New("refresh token expired")
+		}
+
+		now := time.Now()
+		if err := tx.Model(&stored).Update("used_at", now).Error; err != nil {
+			return errors.New("failed to rotate refresh token")
+		}
+
+		var user models.User
+		if err := tx.Where("id = ?", stored.UserID).First(&user).Error; err != nil {
+			return errors.New("user not found")
+		}
+
+		if !user.CanLogin() {
+			return errors.New("Permission denied to login")
+		}
+
+		if claims.TokenVersion != user.TokenVersion {
+			return errors.New("session has been logged out")
+		}
+
+		var session models.UserSession
+		result = tx.Where("family_id = ?", stored.FamilyID).First(&session)
+		if result.Error != nil || session.RevokedAt != nil {
+			return errors.New("session has been revoked")
+		}
+
+		err := tx.Model(&session).UpdateColumns(map[string]interface{}{
+			"last_seen_at": now,
+			"last_seen_ip": ipAddress,
+			"expires_at":   now.Add(config.RefreshTokenTTL()),
+		}).Error
+		if err != nil {
+			return errors.New("failed to rotate refresh token")
+		}
+
+		response, err = ac.issueTokenPair(tx, user, stored.FamilyID)
+		return err
+	})
+
+	if reused {
+		// A rotated token was presented again, so the family may be in the hands of
+		// someone else. Revoke every token of the login and force a new sign in.
+		models.RevokeSession(ac.DB, claims.FamilyID)
+		return nil, errors.New("refresh token reuse detected, please sign in again")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Logout revokes the presented access token and ends its session. A refresh token
+// of another session can be given to end that one as well.
+func (ac *AuthController) Logout(user *models.User, claims *config.JWTClaims, token string, req dto.LogoutRequestDTO) error {
+	if !user.IsTokenBlacklisted(ac.DB, claims.ID) {
+		err := user.BlacklistToken(ac.DB, claims.ID, utils.HashToken(token), claims.ExpiresAt.Time, "logout")
+		if err != nil {
+			return errors.New("failed to revoke token")
+		}
+	}
+
+	if claims.FamilyID != "" {
+		if err := models.RevokeSession(ac.DB, claims.FamilyID); err != nil {
+			return errors.New("failed to end session")
+		}
+	}
+
+	if req.RefreshToken != "" {
+		var stored models.RefreshToken
+		result := ac.DB.Where("token_hash = ? AND user_id = ?", utils.HashToken(req.RefreshToken), user.ID).First(&stored)
+		if result.Error == nil {
+			if err := models.RevokeSession(ac.DB, stored.FamilyID); err != nil {
+				return errors.New("failed to revoke refresh token")
+			}
+		}
+	}
+
+	return nil
+}
+
+// LogoutAllSessions invalidates every token issued to the user
+func (ac *AuthController) LogoutAllSessions(user *models.User, reason string) error {
+	err := ac.DB.Transaction(func(tx *gorm.DB) error {
+		return user.LogoutAllSessions(tx, reason)
+	})
+	if err != nil {
+		return errors.New("failed to logout sessions")
+	}
+
+	return nil
+}
+
+// ForceLogoutUser lets an administrator invalidate every token of another user
+func (ac *AuthController) ForceLogoutUser(actor *models.User, userID uint, req dto.ForceLogoutRequestDTO) error {
+	user, err := ac.findManagedUser(actor, userID)
+	if err != nil {
+		return err
+	}
+
+	reason := fmt.Sprintf("forced by user %d", actor.ID)
+	if strings.TrimSpace(req.Reason) != "" {
+		reason = fmt.Sprintf("%s: %s", reason, strings.TrimSpace(req.Reason))
+	}
+
+	return ac.LogoutAllSessions(user, reason)
+}
+
+// UnlockUser lets an administrator unlock an account before its lockout expires
+func (ac *AuthController) UnlockUser(actor *models.User, userID uint, ipAddress string) error {
+	user, err := ac.findManagedUser(actor, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := user.UnlockAccount(ac.DB); err != nil {
+		return errors.New("failed to unlock account")
 	}
 
-	token, err := utils.GenerateToken(user, "access")
+	user.AddHistory(ac.DB, "ACCOUNT_UNLOCKED", map[string]interface{}{
+		"unlocked_by": actor.ID,
+	}, ipAddress)
 
+	return nil
+}
+
+// issueTokenPair signs a new access token and a refresh token of the given family
+func (ac *AuthController) issueTokenPair(tx *gorm.DB, user models.User, familyID string) (*dto.SignInResponseDTO, error) {
+	accessToken, err := utils.GenerateAccessToken(user, familyID)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	refreshToken, refreshClaims, err := utils.GenerateRefreshToken(user, familyID)
 	if err != nil {
 		return nil, errors.New("failed to generate token")
 	}
 
+	stored := models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: refreshClaims.ExpiresAt.Time,
+	}
+	if err := tx.Create(&stored).Error; err != nil {
+		return nil, errors.New("failed to store refresh token")
+	}
+
 	return &dto.SignInResponseDTO{
-		Token: token,
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(config.AccessTokenTTL().Seconds()),
 	}, nil
-
 }
 
 func (ac *AuthController) SignUp(req dto.SignUpRequestDTO) (*dto.SignUpResponseDTO, error) {
@@ -58,11 +311,87 @@ func (ac *AuthController) SignUp(req dto.SignUpRequestDTO) (*dto.SignUpResponseD
 		return nil, errors.New("failed to create user")
 	}
 
-	// TODO: will implement email sending later
+	if err := ac.sendVerificationEmail(&newUser); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", newUser.Email, err)
+	}
 
 	return &dto.SignUpResponseDTO{
 		IsSuccess: true,
-		Message:   "User created successfully",
+		Message:   "User created successfully, please check your email to verify your account",
 	}, nil
 
 }
+
+func (ac *AuthController) VerifyEmail(req dto.VerifyEmailRequestDTO) error {
+	claims, err := utils.ValidateJWT(req.Token)
+	if err != nil || claims.TokenType != "email_verification" {
+		return errors.New("invalid or expired verification link")
+	}
+
+	var user models.User
+	result := ac.DB.Where("id = ?", claims.UserID).First(&user)
+	if result.RowsAffected == 0 || user.Email != claims.Email {
+		return errors.New("invalid or expired verification link")
+	}
+
+	if user.EmailVerified {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerified = true
+	user.VerifiedAt = &now
+	if err := ac.DB.Save(&user).Error; err != nil {
+		return errors.New("failed to verify email")
+	}
+
+	user.AddHistory(ac.DB, "EMAIL_VERIFIED", map[string]interface{}{
+		"email": user.Email,
+	}, "")
+
+	return nil
+}
+
+// ResendVerificationEmail sends a new verification link unless one was sent recently.
+// It never reveals whether the email belongs to an account.
+func (ac *AuthController) ResendVerificationEmail(req dto.ResendVerificationRequestDTO) error {
+	var user models.User
+	result := ac.DB.Where("email = ?", strings.ToLower(req.Email)).First(&user)
+	if result.RowsAffected == 0 || user.EmailVerified {
+		return nil
+	}
+
+	interval := config.EmailVerificationResendInterval()
+	if user.VerificationSentAt != nil && time.Since(*user.VerificationSentAt) < interval {
+		return nil
+	}
+
+	if err := ac.sendVerificationEmail(&user); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", user.Email, err)
+		return errors.New("failed to send verification email")
+	}
+
+	return nil
+}
+
+func (ac *AuthController) sendVerificationEmail(user *models.User) error {
+	token, err := utils.GenerateEmailVerificationToken(*user)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", config.FrontendURL(), url.QueryEscape(token))
+	err = ac.Mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease verify your email address by opening the link below:\n\n%s\n\nThe link expires in %s.\n",
+			user.FirstName, link, config.EmailVerificationTTL(),
+		),
+	})
+	if err != nil {
+		return err
+	}
+
+	return ac.DB.Model(user).Update("verification_sent_at", time.Now()).Error
+}
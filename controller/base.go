@@ -1,13 +1,31 @@
 package controller
 
-import "gorm.io/gorm"
+import (
+	"context"
+
+	"github.com/farhapartex/ainventory/mailer"
+	"github.com/farhapartex/ainventory/oidc"
+	"gorm.io/gorm"
+)
 
 type AuthController struct {
-	DB *gorm.DB
+	DB     *gorm.DB
+	Mailer mailer.Mailer
+	OIDC   *oidc.Provider // nil when single sign on is not configured
 }
 
-func NewAuthController(db *gorm.DB) *AuthController {
+func NewAuthController(db *gorm.DB, mail mailer.Mailer, provider *oidc.Provider) *AuthController {
 	return &AuthController{
-		DB: db,
+		DB:     db,
+		Mailer: mail,
+		OIDC:   provider,
 	}
 }
+
+// WithContext returns a controller whose queries run in the given context. Business
+// data is only reachable through the active organization the context carries.
+func (ac *AuthController) WithContext(ctx context.Context) *AuthController {
+	scoped := *ac
+	scoped.DB = ac.DB.WithContext(ctx)
+	return &scoped
+}
@@ -0,0 +1,274 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/ainventory/config"
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/mapper"
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/tenant"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RequestElevation asks for a permission in the active organization for a limited
+// number of hours
+func (ac *AuthController) RequestElevation(user *models.User, req dto.ElevationCreateRequestDTO, ipAddress string) (*dto.ElevationResponseDTO, error) {
+	if req.DurationHours > config.ElevationMaxHours() {
+		return nil, fmt.Errorf("elevations can last at most %d hours", config.ElevationMaxHours())
+	}
+
+	organizationID, ok := tenant.OrganizationID(ac.DB.Statement.Context)
+	if !ok {
+		return nil, fmt.Errorf("%w: select the organization you need the permission in", ErrOrganizationAccess)
+	}
+
+	var permission models.Permission
+	result := ac.DB.Where("name = ? AND is_active = ?", req.PermissionName, true).First(&permission)
+	if result.RowsAffected == 0 {
+		return nil, errors.New("permission not found")
+	}
+
+	grants, err := user.PermissionGrants(ac.DB, permission.Name)
+	if err != nil {
+		return nil, errors.New("failed to check current permissions")
+	}
+	for _, grant := range grants {
+		if !grant.IsGranted {
+			return nil, errors.New("this permission is explicitly denied to you")
+		}
+	}
+	if user.IsSuperuser || len(grants) > 0 {
+		return nil, errors.New("you already hold this permission")
+	}
+
+	var pendingCount int64
+	ac.DB.Model(&models.PermissionElevationRequest{}).
+		Where("organization_id = ? AND user_id = ? AND permission_id = ? AND status = ?", organizationID, user.ID, permission.ID, models.ElevationPending).
+		Count(&pendingCount)
+	if pendingCount > 0 {
+		return nil, errors.New("a request for this permission is already pending")
+	}
+
+	elevation := models.PermissionElevationRequest{
+		OrganizationID: organizationID,
+		UserID:         user.ID,
+		PermissionID:   permission.ID,
+		DurationHours:  req.DurationHours,
+		Justification:  strings.TrimSpace(req.Justification),
+		Status:         models.ElevationPending,
+	}
+	if err := ac.DB.Create(&elevation).Error; err != nil {
+		return nil, errors.New("failed to create elevation request")
+	}
+
+	user.AddHistory(ac.DB, "ELEVATION_REQUESTED", map[string]interface{}{
+		"elevation_id":    elevation.ID,
+		"organization_id": organizationID,
+		"permission":      permission.Name,
+		"duration_hours":  elevation.DurationHours,
+	}, ipAddress)
+
+	elevation.User = *user
+	elevation.Permission = permission
+	response := mapper.ElevationModelToDTO(elevation)
+	return &response, nil
+}
+
+// UserElevationList returns the elevation requests of the user, newest first
+func (ac *AuthController) UserElevationList(user *models.User) ([]dto.ElevationResponseDTO, error) {
+	return ac.elevationList(ac.DB.Where("user_id = ?", user.ID))
+}
+
+// ElevationList returns the elevation requests made in the reviewer's active
+// organization, in every organization for superusers, optionally filtered by status
+func (ac *AuthController) ElevationList(reviewer *models.User, status string) ([]dto.ElevationResponseDTO, error) {
+	query := ac.DB
+	if !reviewer.IsSuperuser {
+		organizationID, ok := tenant.OrganizationID(ac.DB.Statement.Context)
+		if !ok {
+			return nil, fmt.Errorf("%w: select an organization to review its elevation requests", ErrOrganizationAccess)
+		}
+		query = query.Where("organization_id = ?", organizationID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	return ac.elevationList(query)
+}
+
+// CancelElevation withdraws a pending request of the user
+func (ac *AuthController) CancelElevation(user *models.User, elevationID uint, ipAddress string) error {
+	result := ac.DB.Model(&models.PermissionElevationRequest{}).
+		Where("id = ? AND user_id = ? AND status = ?", elevationID, user.ID, models.ElevationPending).
+		Update("status", models.ElevationCancelled)
+	if result.Error != nil {
+		return errors.New("failed to cancel elevation request")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("pending elevation request not found")
+	}
+
+	user.AddHistory(ac.DB, "ELEVATION_CANCELLED", map[string]interface{}{
+		"elevation_id": elevationID,
+	}, ipAddress)
+
+	return nil
+}
+
+// ApproveElevation grants the requested permission in the organization it was requested
+// for, until the requested duration has passed
+func (ac *AuthController) ApproveElevation(reviewer *models.User, elevationID uint, req dto.ElevationReviewRequestDTO, ipAddress string) (*dto.ElevationResponseDTO, error) {
+	var elevation models.PermissionElevationRequest
+
+	err := ac.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		elevation, err = lockPendingElevation(tx, reviewer, elevationID)
+		if err != nil {
+			return err
+		}
+
+		if err := ac.checkSameOrganization(reviewer, &elevation.User); err != nil {
+			return err
+		}
+		if err := ac.checkUserAuthority(reviewer, &elevation.User); err != nil {
+			return err
+		}
+		if err := ac.checkGrantAuthority(reviewer, []uint{elevation.PermissionID}); err != nil {
+			return err
+		}
+
+		var existing models.UserPermission
+		result := tx.Where("organization_id = ? AND user_id = ? AND permission_id = ?", elevation.OrganizationID, elevation.UserID, elevation.PermissionID).First(&existing)
+		if result.RowsAffected > 0 && !existing.IsGranted {
+			return ErrExplicitDeny
+		}
+
+		now := time.Now()
+		expiresAt := now.Add(time.Duration(elevation.DurationHours) * time.Hour)
+
+		// Never shorten a grant the user already has
+		if result.RowsAffected > 0 && (existing.ExpiresAt == nil || existing.ExpiresAt.After(expiresAt)) {
+			return errors.New("the user already holds this permission")
+		}
+		reason := fmt.Sprintf("Elevation #%d: %s", elevation.ID, elevation.Justification)
+		if len(reason) > 300 {
+			reason = reason[:300]
+		}
+
+		if err := grantUserPermission(tx, elevation.OrganizationID, elevation.UserID, elevation.PermissionID, reviewer.ID, &expiresAt, reason); err != nil {
+			return errors.New("failed to grant permission")
+		}
+
+		var grant models.UserPermission
+		tx.Where("organization_id = ? AND user_id = ? AND permission_id = ?", elevation.OrganizationID, elevation.UserID, elevation.PermissionID).First(&grant)
+
+		elevation.Status = models.ElevationApproved
+		elevation.ReviewedBy = &reviewer.ID
+		elevation.ReviewedAt = &now
+		elevation.ReviewNote = req.Note
+		elevation.ExpiresAt = &expiresAt
+		elevation.UserPermissionID = &grant.ID
+		if err := tx.Omit(clause.Associations).Save(&elevation).Error; err != nil {
+			return errors.New("failed to approve elevation request")
+		}
+
+		return elevation.User.AddHistory(tx, "ELEVATION_APPROVED", map[string]interface{}{
+			"elevation_id": elevation.ID,
+			"permission":   elevation.Permission.Name,
+			"approved_by":  reviewer.ID,
+			"expires_at":   expiresAt,
+		}, ipAddress)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapper.ElevationModelToDTO(elevation)
+	return &response, nil
+}
+
+// DenyElevation closes a pending request without granting anything
+func (ac *AuthController) DenyElevation(reviewer *models.User, elevationID uint, req dto.ElevationReviewRequestDTO, ipAddress string) (*dto.ElevationResponseDTO, error) {
+	var elevation models.PermissionElevationRequest
+
+	err := ac.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		elevation, err = lockPendingElevation(tx, reviewer, elevationID)
+		if err != nil {
+			return err
+		}
+
+		if err := ac.checkSameOrganization(reviewer, &elevation.User); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		elevation.Status = models.ElevationDenied
+		elevation.ReviewedBy = &reviewer.ID
+		elevation.ReviewedAt = &now
+		elevation.ReviewNote = req.Note
+		if err := tx.Omit(clause.Associations).Save(&elevation).Error; err != nil {
+			return errors.New("failed to deny elevation request")
+		}
+
+		return elevation.User.AddHistory(tx, "ELEVATION_DENIED", map[string]interface{}{
+			"elevation_id": elevation.ID,
+			"permission":   elevation.Permission.Name,
+			"denied_by":    reviewer.ID,
+		}, ipAddress)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapper.ElevationModelToDTO(elevation)
+	return &response, nil
+}
+
+// lockPendingElevation loads a pending request for review. Nobody reviews their own
+// request, and only superusers review requests outside their active organization.
+func lockPendingElevation(tx *gorm.DB, reviewer *models.User, elevationID uint) (models.PermissionElevationRequest, error) {
+	var elevation models.PermissionElevationRequest
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", elevationID).First(&elevation)
+	if result.Error != nil {
+		return elevation, errors.New("elevation request not found")
+	}
+
+	organizationID, ok := tenant.OrganizationID(tx.Statement.Context)
+	if !reviewer.IsSuperuser && (!ok || organizationID != elevation.OrganizationID) {
+		return elevation, errors.New("elevation request not found")
+	}
+
+	if elevation.Status != models.ElevationPending {
+		return elevation, errors.New("elevation request has already been reviewed")
+	}
+
+	if elevation.UserID == reviewer.ID {
+		return elevation, errors.New("you cannot review your own elevation request")
+	}
+
+	tx.Where("id = ?", elevation.UserID).First(&elevation.User)
+	tx.Where("id = ?", elevation.PermissionID).First(&elevation.Permission)
+
+	return elevation, nil
+}
+
+func (ac *AuthController) elevationList(query *gorm.DB) ([]dto.ElevationResponseDTO, error) {
+	var elevations []models.PermissionElevationRequest
+	err := query.Preload("User").Preload("Permission").Order("created_at DESC").Find(&elevations).Error
+	if err != nil {
+		return nil, errors.New("error retrieving elevation requests")
+	}
+
+	responseDTOs := make([]dto.ElevationResponseDTO, 0, len(elevations))
+	for _, elevation := range elevations {
+		responseDTOs = append(responseDTOs, mapper.ElevationModelToDTO(elevation))
+	}
+
+	return responseDTOs, nil
+}
@@ -0,0 +1,272 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/ainventory/config"
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/oidc"
+	"github.com/farhapartex/ainventory/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrOIDCDisabled = errors.New("single sign on is not configured")
+
+// OIDCLogin starts an authorization code flow with PKCE and returns the identity
+// provider URL to send the browser to
+func (ac *AuthController) OIDCLogin(ctx context.Context) (*dto.OIDCLoginResponseDTO, error) {
+	if ac.OIDC == nil {
+		return nil, ErrOIDCDisabled
+	}
+
+	state, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return nil, errors.New("failed to start single sign on")
+	}
+	nonce, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return nil, errors.New("failed to start single sign on")
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return nil, errors.New("failed to start single sign on")
+	}
+
+	authorizationURL, err := ac.OIDC.AuthCodeURL(ctx, state, nonce, oidc.CodeChallengeS256(verifier))
+	if err != nil {
+		log.Printf("OIDC login failed: %v", err)
+		return nil, errors.New("identity provider is unavailable")
+	}
+
+	loginState := models.OIDCLoginState{
+		StateHash:    utils.HashToken(state),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(config.OIDCStateTTL()),
+	}
+	if err := ac.DB.Create(&loginState).Error; err != nil {
+		return nil, errors.New("failed to start single sign on")
+	}
+
+	return &dto.OIDCLoginResponseDTO{
+		AuthorizationURL: authorizationURL,
+		State:            state,
+	}, nil
+}
+
+// OIDCCallback finishes the flow started by OIDCLogin. The user is found by their
+// identity, linked by verified email or provisioned, and receives the usual token
+// pair. Users with two factor enabled still complete it through /auth/2fa/verify/.
+func (ac *AuthController) OIDCCallback(ctx context.Context, req dto.OIDCCallbackRequestDTO, ipAddress, userAgent string) (*dto.SignInResponseDTO, error) {
+	if ac.OIDC == nil {
+		return nil, ErrOIDCDisabled
+	}
+
+	var loginState models.OIDCLoginState
+	err := ac.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("state_hash = ?", utils.HashToken(req.State)).
+			First(&loginState)
+		if result.Error != nil || loginState.UsedAt != nil || time.Now().After(loginState.ExpiresAt) {
+			return errors.New("invalid or expired single sign on request")
+		}
+		return tx.Model(&loginState).Update("used_at", time.Now()).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := ac.OIDC.Exchange(ctx, req.Code, loginState.CodeVerifier)
+	if err != nil {
+		log.Printf("OIDC callback failed: %v", err)
+		return nil, errors.New("failed to complete single sign on")
+	}
+
+	claims, err := ac.OIDC.VerifyIDToken(ctx, token.IDToken, loginState.Nonce)
+	if err != nil {
+		log.Printf("OIDC callback failed: %v", err)
+		return nil, errors.New("failed to complete single sign on")
+	}
+
+	user, err := ac.oidcUser(claims, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.AccountLocked {
+		if !user.IsLockExpired(config.LoginLockoutDuration()) {
+			return nil, ErrAccountLocked
+		}
+		if err := user.UnlockAccount(ac.DB); err != nil {
+			return nil, errors.New("failed to unlock account")
+		}
+	}
+
+	if !user.CanLogin() {
+		return nil, errors.New("Permission denied to login")
+	}
+
+	if err := ac.syncOIDCRole(user, claims.Groups, ipAddress); err != nil {
+		log.Printf("Failed to sync role of %s from identity provider groups: %v", user.Email, err)
+	}
+
+	user.AddHistory(ac.DB, "OIDC_LOGIN", map[string]interface{}{
+		"issuer": claims.Issuer,
+	}, ipAddress)
+
+	if user.TwoFactorEnabled {
+		mfaToken, err := utils.GenerateMFAToken(*user)
+		if err != nil {
+			return nil, errors.New("failed to generate token")
+		}
+		return &dto.SignInResponseDTO{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}, nil
+	}
+
+	return ac.completeSignIn(user, ipAddress, userAgent)
+}
+
+// oidcUser returns the user of an identity. Unknown identities are linked to the
+// account with the same email if that account verified it, or provisioned when
+// that is enabled. An unverified account may have been registered by someone else
+// ahead of the real owner, linking it would let their password in as well.
+func (ac *AuthController) oidcUser(claims *oidc.Claims, ipAddress string) (*models.User, error) {
+	now := time.Now()
+
+	var identity models.UserIdentity
+	result := ac.DB.Where("issuer = ? AND subject = ?", claims.Issuer, claims.Subject).First(&identity)
+	if result.RowsAffected > 0 {
+		var user models.User
+		if err := ac.DB.Where("id = ?", identity.UserID).First(&user).Error; err != nil {
+			return nil, errors.New("Permission denied to login")
+		}
+
+		ac.DB.Model(&identity).Updates(map[string]interface{}{
+			"email":         claims.Email,
+			"last_login_at": now,
+		})
+		return &user, nil
+	}
+
+	// Matching by email is only safe when the provider vouches for the address
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, errors.New("identity provider did not return a verified email")
+	}
+
+	var user models.User
+	created := false
+	err := ac.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("email = ?", claims.Email).First(&user)
+		if result.RowsAffected == 0 {
+			if !ac.OIDC.AutoProvision {
+				return errors.New("no account exists for this identity")
+			}
+
+			password, err := utils.GenerateRandomString(32)
+			if err != nil {
+				return errors.New("failed to create user")
+			}
+
+			user = models.User{
+				FirstName:     oidcFirstName(claims),
+				LastName:      claims.FamilyName,
+				Email:         claims.Email,
+				Password:      password, // Never shared, the account signs in through the provider
+				Status:        "active",
+				EmailVerified: true,
+				VerifiedAt:    &now,
+				Source:        "oidc",
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return errors.New("failed to create user")
+			}
+			created = true
+		} else if user.IsServiceAccount {
+			return errors.New("Permission denied to login")
+		} else if !user.EmailVerified {
+			return errors.New("verify the email of your account before signing in with single sign on")
+		}
+
+		identity = models.UserIdentity{
+			UserID:      user.ID,
+			Issuer:      claims.Issuer,
+			Subject:     claims.Subject,
+			Email:       claims.Email,
+			LastLoginAt: &now,
+		}
+		if err := tx.Create(&identity).Error; err != nil {
+			return errors.New("failed to link identity")
+		}
+
+		action := "OIDC_LINKED"
+		if created {
+			action = "OIDC_PROVISIONED"
+		}
+		return user.AddHistory(tx, action, map[string]interface{}{
+			"issuer":  claims.Issuer,
+			"subject": claims.Subject,
+		}, ipAddress)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// syncOIDCRole gives the user the highest level role their IdP groups map to, in the
+// organization they sign in to. Users whose groups map to no role keep the role they
+// have, users without an organization get none.
+func (ac *AuthController) syncOIDCRole(user *models.User, groups []string, ipAddress string) error {
+	names := ac.OIDC.MappedRoles(groups)
+	if len(names) == 0 {
+		return nil
+	}
+
+	organizationID := user.DefaultOrganizationID(ac.DB)
+	if organizationID == 0 {
+		return nil
+	}
+
+	var role models.Role
+	result := ac.DB.Where("name IN ? AND is_active = ?", names, true).Order("level DESC").First(&role)
+	if result.RowsAffected == 0 {
+		return errors.New("no active role matches " + strings.Join(names, ", "))
+	}
+
+	var membership models.OrganizationMember
+	if err := ac.DB.Where("organization_id = ? AND user_id = ?", organizationID, user.ID).First(&membership).Error; err != nil {
+		return err
+	}
+	if membership.RoleID != nil && *membership.RoleID == role.ID {
+		return nil
+	}
+
+	previousRoleID := membership.RoleID
+	if err := membership.AssignRole(ac.DB, &role.ID); err != nil {
+		return err
+	}
+
+	return user.AddHistory(ac.DB, "ROLE_ASSIGNED", map[string]interface{}{
+		"organization_id":  organizationID,
+		"previous_role_id": previousRoleID,
+		"role_id":          role.ID,
+		"source":           "oidc_groups",
+	}, ipAddress)
+}
+
+func oidcFirstName(claims *oidc.Claims) string {
+	if claims.GivenName != "" {
+		return claims.GivenName
+	}
+	local, _, _ := strings.Cut(claims.Email, "@")
+	return local
+}
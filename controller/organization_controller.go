@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/models"
+	"gorm.io/gorm"
+)
+
+// ErrOrganizationAccess is returned when the user's organization role does not allow the action
+var ErrOrganizationAccess = errors.New("organization access denied")
+
+// ErrLastOwner is returned when a change would leave an organization without an owner
+var ErrLastOwner = errors.New("an organization must keep at least one owner")
+
+// UpdatePasswordPolicy changes how long passwords stay valid for the organization's
+// users and recalculates their expiry dates
+func (ac *AuthController) UpdatePasswordPolicy(user *models.User, organizationID uint, req dto.PasswordPolicyRequestDTO) (*dto.PasswordPolicyResponseDTO, error) {
+	organization, err := ac.findManagedOrganization(user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ac.DB.Transaction(func(tx *gorm.DB) error {
+		organization.PasswordMaxAgeDays = *req.PasswordMaxAgeDays
+		err := tx.Model(organization).Update("password_max_age_days", organization.PasswordMaxAgeDays).Error
+		if err != nil {
+			return err
+		}
+
+		var users []models.User
+		err = tx.Where("id IN (?)", tx.Model(&models.OrganizationMember{}).Select("user_id").Where("organization_id = ?", organization.ID)).
+			Find(&users).Error
+		if err != nil {
+			return err
+		}
+
+		for _, member := range users {
+			if err := refreshPasswordExpiry(tx, &member); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.New("failed to update password policy")
+	}
+
+	return &dto.PasswordPolicyResponseDTO{
+		OrganizationID:     organization.ID,
+		PasswordMaxAgeDays: organization.PasswordMaxAgeDays,
+	}, nil
+}
+
+// findOrganizationMembership loads an organization together with the user's membership.
+// Superusers are treated as owners of every organization.
+func (ac *AuthController) findOrganizationMembership(user *models.User, organizationID uint) (*models.Organization, *models.OrganizationMember, error) {
+	var organization models.Organization
+	result := ac.DB.Where("id = ?", organizationID).First(&organization)
+	if result.RowsAffected == 0 {
+		return nil, nil, errors.New("organization not found")
+	}
+
+	var membership models.OrganizationMember
+	result = ac.DB.Where("organization_id = ? AND user_id = ?", organization.ID, user.ID).First(&membership)
+	if result.RowsAffected == 0 {
+		if !user.IsSuperuser {
+			return nil, nil, errors.New("organization not found")
+		}
+		membership = models.OrganizationMember{OrganizationID: organization.ID, UserID: user.ID, Role: models.OrganizationRoleOwner}
+	}
+
+	return &organization, &membership, nil
+}
+
+// findManagedOrganization loads an organization the user is allowed to administer
+func (ac *AuthController) findManagedOrganization(user *models.User, organizationID uint) (*models.Organization, error) {
+	organization, membership, err := ac.findOrganizationMembership(user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !membership.CanManage() {
+		return nil, fmt.Errorf("%w: only organization owners and admins can manage this organization", ErrOrganizationAccess)
+	}
+
+	return organization, nil
+}
+
+// refreshPasswordExpiry recalculates the password expiry of a user whose
+// organizations or their policies changed
+func refreshPasswordExpiry(tx *gorm.DB, user *models.User) error {
+	user.ApplyPasswordExpiry(tx)
+	return tx.Model(user).Update("password_expires_at", user.PasswordExpiresAt).Error
+}
@@ -0,0 +1,236 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/farhapartex/ainventory/config"
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/jobs"
+	"github.com/farhapartex/ainventory/mapper"
+	"github.com/farhapartex/ainventory/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrExportInProgress is returned while an export of the organization is still being built
+var ErrExportInProgress = errors.New("an export of this organization is in progress")
+
+// ErrExportNotReady is returned when downloading an export that has not completed
+var ErrExportNotReady = errors.New("the export has not completed")
+
+// ErrExportRequired is returned when purging an organization whose data was never exported
+var ErrExportRequired = errors.New("export the organization's data before purging it, or set skip_export")
+
+// CreateOrganizationExport queues an archive of all the organization's data. It
+// is built in the background, poll the export until it completes.
+func (ac *AuthController) CreateOrganizationExport(user *models.User, organizationID uint, ipAddress string) (*dto.OrganizationExportResponseDTO, error) {
+	organization, err := ac.findExportingOrganization(user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := models.OrganizationExport{
+		OrganizationID: organization.ID,
+		RequestedBy:    user.ID,
+		Status:         models.ExportStatusPending,
+	}
+	err = ac.DB.Transaction(func(tx *gorm.DB) error {
+		// Serializes export requests of the organization
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", organization.ID).First(&models.Organization{}).Error; err != nil {
+			return err
+		}
+
+		var active int64
+		tx.Model(&models.OrganizationExport{}).
+			Where("organization_id = ? AND status IN ?", organization.ID, []string{models.ExportStatusPending, models.ExportStatusRunning}).
+			Count(&active)
+		if active > 0 {
+			return ErrExportInProgress
+		}
+
+		if err := tx.Create(&export).Error; err != nil {
+			return err
+		}
+
+		return user.AddHistory(tx, "ORGANIZATION_EXPORT_REQUESTED", map[string]interface{}{
+			"organization_id": organization.ID,
+			"export_id":       export.ID,
+		}, ipAddress)
+	})
+	if errors.Is(err, ErrExportInProgress) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("failed to create export")
+	}
+
+	go jobs.RunOrganizationExport(ac.DB, export.ID, config.OrganizationExportDir())
+
+	response := mapper.OrganizationExportModelToDTO(export)
+	return &response, nil
+}
+
+func (ac *AuthController) OrganizationExportList(user *models.User, organizationID uint) ([]dto.OrganizationExportResponseDTO, error) {
+	organization, err := ac.findExportingOrganization(user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var exports []models.OrganizationExport
+	if err := ac.DB.Where("organization_id = ?", organization.ID).Order("created_at DESC").Find(&exports).Error; err != nil {
+		return nil, errors.New("error retrieving exports")
+	}
+
+	responseDTOs := make([]dto.OrganizationExportResponseDTO, 0, len(exports))
+	for _, export := range exports {
+		responseDTOs = append(responseDTOs, mapper.OrganizationExportModelToDTO(export))
+	}
+
+	return responseDTOs, nil
+}
+
+func (ac *AuthController) OrganizationExportGet(user *models.User, organizationID, exportID uint) (*dto.OrganizationExportResponseDTO, error) {
+	export, err := ac.findOrganizationExport(user, organizationID, exportID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapper.OrganizationExportModelToDTO(*export)
+	return &response, nil
+}
+
+// OrganizationExportFile returns the path of a completed export archive and the
+// file name to download it as
+func (ac *AuthController) OrganizationExportFile(user *models.User, organizationID, exportID uint, ipAddress string) (string, string, error) {
+	export, err := ac.findOrganizationExport(user, organizationID, exportID)
+	if err != nil {
+		return "", "", err
+	}
+
+	if export.Status != models.ExportStatusCompleted {
+		return "", "", ErrExportNotReady
+	}
+	if _, err := os.Stat(export.FilePath); err != nil {
+		return "", "", errors.New("export archive is no longer available")
+	}
+
+	user.AddHistory(ac.DB, "ORGANIZATION_EXPORT_DOWNLOADED", map[string]interface{}{
+		"organization_id": export.OrganizationID,
+		"export_id":       export.ID,
+	}, ipAddress)
+
+	return export.FilePath, fmt.Sprintf("organization-%d-export-%d.zip", export.OrganizationID, export.ID), nil
+}
+
+// PurgeOrganization irreversibly deletes the organization and everything that
+// belongs to it. Only superusers can purge, the request must repeat the
+// organization's name and, unless skipped, its data must have been exported.
+func (ac *AuthController) PurgeOrganization(user *models.User, organizationID uint, req dto.OrganizationPurgeRequestDTO, ipAddress string) (*dto.OrganizationPurgeResponseDTO, error) {
+	if !user.IsSuperuser {
+		return nil, fmt.Errorf("%w: only superusers can purge an organization", ErrOrganizationAccess)
+	}
+
+	var organization models.Organization
+	result := ac.DB.Where("id = ?", organizationID).First(&organization)
+	if result.RowsAffected == 0 {
+		return nil, errors.New("organization not found")
+	}
+
+	if req.Confirm != organization.Name {
+		return nil, errors.New("confirm must match the organization name")
+	}
+
+	var archives []string
+	var deleted map[string]int64
+	err := ac.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", organization.ID).First(&organization).Error; err != nil {
+			return err
+		}
+
+		var exports []models.OrganizationExport
+		if err := tx.Where("organization_id = ?", organization.ID).Order("id DESC").Find(&exports).Error; err != nil {
+			return err
+		}
+		exported := false
+		for _, export := range exports {
+			if export.IsActive() {
+				return ErrExportInProgress
+			}
+			if export.Status == models.ExportStatusCompleted {
+				exported = true
+			}
+			if export.FilePath != "" {
+				archives = append(archives, export.FilePath)
+			}
+		}
+		if !exported && !req.SkipExport {
+			return ErrExportRequired
+		}
+
+		var err error
+		deleted, err = models.PurgeOrganization(tx, organization.ID, user.ID)
+		if err != nil {
+			return err
+		}
+
+		return user.AddHistory(tx, "ORGANIZATION_PURGED", map[string]interface{}{
+			"organization_id":   organization.ID,
+			"organization_name": organization.Name,
+			"exported":          exported,
+			"deleted":           deleted,
+		}, ipAddress)
+	})
+	if errors.Is(err, ErrExportInProgress) || errors.Is(err, ErrExportRequired) {
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("Purging organization %d failed: %v", organization.ID, err)
+		return nil, errors.New("failed to purge organization")
+	}
+
+	// The archives hold the organization's data as well
+	for _, archive := range archives {
+		if err := os.Remove(archive); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove export archive %s: %v", archive, err)
+		}
+	}
+	log.Printf("Organization %d (%s) purged by user %d", organization.ID, organization.Name, user.ID)
+
+	return &dto.OrganizationPurgeResponseDTO{
+		OrganizationID: organization.ID,
+		Deleted:        deleted,
+	}, nil
+}
+
+// findExportingOrganization loads an organization whose data the user may export,
+// which takes an owner
+func (ac *AuthController) findExportingOrganization(user *models.User, organizationID uint) (*models.Organization, error) {
+	organization, membership, err := ac.findOrganizationMembership(user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if membership.Role != models.OrganizationRoleOwner {
+		return nil, fmt.Errorf("%w: only organization owners can export its data", ErrOrganizationAccess)
+	}
+
+	return organization, nil
+}
+
+func (ac *AuthController) findOrganizationExport(user *models.User, organizationID, exportID uint) (*models.OrganizationExport, error) {
+	organization, err := ac.findExportingOrganization(user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var export models.OrganizationExport
+	result := ac.DB.Where("id = ? AND organization_id = ?", exportID, organization.ID).First(&export)
+	if result.RowsAffected == 0 {
+		return nil, errors.New("export not found")
+	}
+
+	return &export, nil
+}
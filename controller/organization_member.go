@@ -0,0 +1,396 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/ainventory/config"
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/mailer"
+	"github.com/farhapartex/ainventory/mapper"
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (ac *AuthController) OrganizationMemberList(user *models.User, organizationID uint) ([]dto.OrganizationMemberResponseDTO, error) {
+	organization, _, err := ac.findOrganizationMembership(user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []models.OrganizationMember
+	err = ac.DB.Where("organization_id = ?", organization.ID).Preload("User").Order("created_at ASC").Find(&members).Error
+	if err != nil {
+		return nil, errors.New("error retrieving members")
+	}
+
+	responseDTOs := make([]dto.OrganizationMemberResponseDTO, 0, len(members))
+	for _, member := range members {
+		responseDTOs = append(responseDTOs, mapper.OrganizationMemberModelToDTO(member))
+	}
+
+	return responseDTOs, nil
+}
+
+// InviteOrganizationMember emails a single use link to join the organization.
+// Inviting the same address again replaces the previous pending invitation.
+func (ac *AuthController) InviteOrganizationMember(user *models.User, organizationID uint, req dto.OrganizationInvitationRequestDTO, ipAddress string) (*dto.OrganizationInvitationResponseDTO, error) {
+	organization, membership, err := ac.findOrganizationMembership(user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOrganizationRoleAuthority(membership, req.Role); err != nil {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	var memberCount int64
+	ac.DB.Model(&models.OrganizationMember{}).
+		Joins("JOIN users ON users.id = organization_members.user_id").
+		Where("organization_members.organization_id = ? AND users.email = ?", organization.ID, email).
+		Count(&memberCount)
+	if memberCount > 0 {
+		return nil, errors.New("this user is already a member of the organization")
+	}
+
+	token, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return nil, errors.New("failed to generate invitation token")
+	}
+
+	invitation := models.OrganizationInvitation{
+		OrganizationID: organization.ID,
+		Email:          email,
+		Role:           req.Role,
+		TokenHash:      utils.HashToken(token),
+		Status:         models.InvitationStatusPending,
+		ExpiresAt:      time.Now().Add(config.OrganizationInvitationTTL()),
+		InvitedBy:      user.ID,
+	}
+
+	err = ac.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.OrganizationInvitation{}).
+			Where("organization_id = ? AND email = ? AND status = ?", organization.ID, email, models.InvitationStatusPending).
+			Update("status", models.InvitationStatusRevoked).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Create(&invitation).Error; err != nil {
+			return err
+		}
+
+		return user.AddHistory(tx, "ORGANIZATION_INVITATION_SENT", map[string]interface{}{
+			"organization_id": organization.ID,
+			"invitation_id":   invitation.ID,
+			"email":           email,
+			"role":            req.Role,
+		}, ipAddress)
+	})
+	if err != nil {
+		return nil, errors.New("failed to create invitation")
+	}
+
+	link := fmt.Sprintf("%s/accept-invitation?token=%s", config.FrontendURL(), url.QueryEscape(token))
+	err = ac.Mailer.Send(mailer.Message{
+		To:      email,
+		Subject: fmt.Sprintf("You have been invited to join %s", organization.Name),
+		Body: fmt.Sprintf(
+			"Hi,\n\n%s %s invited you to join %s as %s. Open the link below to accept the invitation, you will be asked to sign in or create an account with this email address:\n\n%s\n\nThe link expires in %s.\n",
+			user.FirstName, user.LastName, organization.Name, req.Role, link, config.OrganizationInvitationTTL(),
+		),
+	})
+	if err != nil {
+		log.Printf("Failed to send organization invitation to %s: %v", email, err)
+		return nil, errors.New("failed to send invitation email")
+	}
+
+	response := mapper.OrganizationInvitationModelToDTO(invitation)
+	return &response, nil
+}
+
+func (ac *AuthController) OrganizationInvitationList(user *models.User, organizationID uint) ([]dto.OrganizationInvitationResponseDTO, error) {
+	organization, err := ac.findManagedOrganization(user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var invitations []models.OrganizationInvitation
+	if err := ac.DB.Where("organization_id = ?", organization.ID).Order("created_at DESC").Find(&invitations).Error; err != nil {
+		return nil, errors.New("error retrieving invitations")
+	}
+
+	responseDTOs := make([]dto.OrganizationInvitationResponseDTO, 0, len(invitations))
+	for _, invitation := range invitations {
+		responseDTOs = append(responseDTOs, mapper.OrganizationInvitationModelToDTO(invitation))
+	}
+
+	return responseDTOs, nil
+}
+
+func (ac *AuthController) RevokeOrganizationInvitation(user *models.User, organizationID, invitationID uint, ipAddress string) error {
+	organization, err := ac.findManagedOrganization(user, organizationID)
+	if err != nil {
+		return err
+	}
+
+	var invitation models.OrganizationInvitation
+	result := ac.DB.Where("id = ? AND organization_id = ?", invitationID, organization.ID).First(&invitation)
+	if result.RowsAffected == 0 {
+		return errors.New("invitation not found")
+	}
+
+	if invitation.EffectiveStatus() != models.InvitationStatusPending {
+		return errors.New("only pending invitations can be revoked")
+	}
+
+	if err := ac.DB.Model(&invitation).Update("status", models.InvitationStatusRevoked).Error; err != nil {
+		return errors.New("failed to revoke invitation")
+	}
+
+	user.AddHistory(ac.DB, "ORGANIZATION_INVITATION_REVOKED", map[string]interface{}{
+		"organization_id": organization.ID,
+		"invitation_id":   invitation.ID,
+		"email":           invitation.Email,
+	}, ipAddress)
+
+	return nil
+}
+
+// AcceptOrganizationInvitation adds the signed in user to the organization of an
+// emailed invitation. The invitation must have been sent to the user's email.
+func (ac *AuthController) AcceptOrganizationInvitation(user *models.User, req dto.OrganizationInvitationAcceptRequestDTO, ipAddress string) (*dto.OrganizationDTO, error) {
+	var response *dto.OrganizationDTO
+	err := ac.DB.Transaction(func(tx *gorm.DB) error {
+		var invitation models.OrganizationInvitation
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", utils.HashToken(req.Token)).
+			Preload("Organization").
+			First(&invitation)
+		if result.Error != nil || invitation.EffectiveStatus() != models.InvitationStatusPending {
+			return errors.New("invalid or expired invitation")
+		}
+
+		if !strings.EqualFold(invitation.Email, user.Email) {
+			return errors.New("this invitation was sent to a different email address")
+		}
+
+		now := time.Now()
+		err := tx.Model(&invitation).Updates(map[string]interface{}{
+			"status":      models.InvitationStatusAccepted,
+			"accepted_by": user.ID,
+			"accepted_at": now,
+		}).Error
+		if err != nil {
+			return errors.New("failed to accept invitation")
+		}
+
+		// Someone who joined in the meantime keeps the roles they already have,
+		// new members start with the default role
+		membership := models.OrganizationMember{
+			OrganizationID: invitation.OrganizationID,
+			UserID:         user.ID,
+			Role:           invitation.Role,
+			RoleID:         models.DefaultRoleID(tx),
+			InvitedBy:      &invitation.InvitedBy,
+		}
+		result = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "organization_id"}, {Name: "user_id"}},
+			DoNothing: true,
+		}).Create(&membership)
+		if result.Error != nil {
+			return errors.New("failed to accept invitation")
+		}
+		if result.RowsAffected > 0 && membership.RoleID != nil {
+			role := models.Role{ID: *membership.RoleID}
+			if err := role.UpdateUserCount(tx); err != nil {
+				return errors.New("failed to accept invitation")
+			}
+		}
+
+		if err := refreshPasswordExpiry(tx, user); err != nil {
+			return errors.New("failed to accept invitation")
+		}
+
+		err = user.AddHistory(tx, "ORGANIZATION_JOINED", map[string]interface{}{
+			"organization_id": invitation.OrganizationID,
+			"invitation_id":   invitation.ID,
+			"role":            invitation.Role,
+		}, ipAddress)
+		if err != nil {
+			return errors.New("failed to accept invitation")
+		}
+
+		response = mapper.OrganizationModelToDTO(&invitation.Organization)
+		response.Role = invitation.Role
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// UpdateOrganizationMember changes the organization role of a member
+func (ac *AuthController) UpdateOrganizationMember(user *models.User, organizationID, memberUserID uint, req dto.OrganizationMemberRoleRequestDTO, ipAddress string) (*dto.OrganizationMemberResponseDTO, error) {
+	organization, membership, err := ac.findOrganizationMembership(user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOrganizationRoleAuthority(membership, req.Role); err != nil {
+		return nil, err
+	}
+
+	var response dto.OrganizationMemberResponseDTO
+	err = ac.DB.Transaction(func(tx *gorm.DB) error {
+		var target models.OrganizationMember
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("organization_id = ? AND user_id = ?", organization.ID, memberUserID).
+			Preload("User").
+			First(&target)
+		if result.RowsAffected == 0 {
+			return errors.New("member not found")
+		}
+		if err := checkOrganizationRoleAuthority(membership, target.Role); err != nil {
+			return err
+		}
+
+		if target.Role == req.Role {
+			response = mapper.OrganizationMemberModelToDTO(target)
+			return nil
+		}
+
+		if target.Role == models.OrganizationRoleOwner {
+			if err := ensureAnotherOwner(tx, organization.ID, target.UserID); err != nil {
+				return err
+			}
+		}
+
+		previousRole := target.Role
+		if err := tx.Model(&target).Update("role", req.Role).Error; err != nil {
+			return errors.New("failed to update member")
+		}
+
+		err := user.AddHistory(tx, "ORGANIZATION_MEMBER_ROLE_CHANGED", map[string]interface{}{
+			"organization_id": organization.ID,
+			"member_id":       target.UserID,
+			"previous_role":   previousRole,
+			"role":            req.Role,
+		}, ipAddress)
+		if err != nil {
+			return errors.New("failed to update member")
+		}
+
+		response = mapper.OrganizationMemberModelToDTO(target)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// RemoveOrganizationMember takes a user out of the organization. Members can
+// always remove themselves, unless they are its last owner.
+func (ac *AuthController) RemoveOrganizationMember(user *models.User, organizationID, memberUserID uint, ipAddress string) error {
+	organization, membership, err := ac.findOrganizationMembership(user, organizationID)
+	if err != nil {
+		return err
+	}
+
+	return ac.DB.Transaction(func(tx *gorm.DB) error {
+		var target models.OrganizationMember
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("organization_id = ? AND user_id = ?", organization.ID, memberUserID).
+			Preload("User").
+			First(&target)
+		if result.RowsAffected == 0 {
+			return errors.New("member not found")
+		}
+
+		if target.UserID != user.ID {
+			if err := checkOrganizationRoleAuthority(membership, target.Role); err != nil {
+				return err
+			}
+		}
+
+		if target.Role == models.OrganizationRoleOwner {
+			if err := ensureAnotherOwner(tx, organization.ID, target.UserID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Delete(&target).Error; err != nil {
+			return errors.New("failed to remove member")
+		}
+
+		// The role and direct grants in the organization end with the membership
+		err := tx.Where("organization_id = ? AND user_id = ?", organization.ID, target.UserID).Delete(&models.UserPermission{}).Error
+		if err != nil {
+			return errors.New("failed to remove member")
+		}
+		if target.RoleID != nil {
+			role := models.Role{ID: *target.RoleID}
+			if err := role.UpdateUserCount(tx); err != nil {
+				return errors.New("failed to remove member")
+			}
+		}
+
+		if err := refreshPasswordExpiry(tx, &target.User); err != nil {
+			return errors.New("failed to remove member")
+		}
+
+		err = user.AddHistory(tx, "ORGANIZATION_MEMBER_REMOVED", map[string]interface{}{
+			"organization_id": organization.ID,
+			"member_id":       target.UserID,
+			"role":            target.Role,
+		}, ipAddress)
+		if err != nil {
+			return errors.New("failed to remove member")
+		}
+
+		return nil
+	})
+}
+
+// checkOrganizationRoleAuthority refuses to let a member work with a role they may
+// not hand out. Admins manage admins and members, only owners manage owners.
+func checkOrganizationRoleAuthority(membership *models.OrganizationMember, role string) error {
+	if !membership.CanManage() {
+		return fmt.Errorf("%w: only organization owners and admins can manage members", ErrOrganizationAccess)
+	}
+	if role == models.OrganizationRoleOwner && membership.Role != models.OrganizationRoleOwner {
+		return fmt.Errorf("%w: only organization owners can manage owners", ErrOrganizationAccess)
+	}
+	return nil
+}
+
+// ensureAnotherOwner fails when the user is the only owner of the organization. The
+// owner rows stay locked until the transaction ends so two owners cannot demote each
+// other at the same time.
+func ensureAnotherOwner(tx *gorm.DB, organizationID, userID uint) error {
+	var owners []models.OrganizationMember
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("organization_id = ? AND role = ?", organizationID, models.OrganizationRoleOwner).
+		Order("id").
+		Find(&owners).Error
+	if err != nil {
+		return err
+	}
+
+	for _, owner := range owners {
+		if owner.UserID != userID {
+			return nil
+		}
+	}
+	return ErrLastOwner
+}
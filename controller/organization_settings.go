@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/mapper"
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/tenant"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrganizationSettingsGet returns the defaults new records of the organization inherit
+func (ac *AuthController) OrganizationSettingsGet(user *models.User, organizationID uint) (*dto.OrganizationSettingsResponseDTO, error) {
+	organization, _, err := ac.findOrganizationMembership(user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := models.SettingsFor(ac.organizationDB(organization.ID))
+	settings.OrganizationID = organization.ID
+
+	response := mapper.OrganizationSettingsModelToDTO(settings)
+	return &response, nil
+}
+
+// UpdateOrganizationSettings changes the given settings of the organization. Only
+// records created afterwards are affected.
+func (ac *AuthController) UpdateOrganizationSettings(user *models.User, organizationID uint, req dto.OrganizationSettingsRequestDTO, ipAddress string) (*dto.OrganizationSettingsResponseDTO, error) {
+	organization, err := ac.findManagedOrganization(user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Normalize()
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var settings models.OrganizationSettings
+	err = ac.organizationDB(organization.ID).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Limit(1).Find(&settings)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			settings = models.DefaultOrganizationSettings()
+			settings.OrganizationID = organization.ID
+		}
+
+		changes := map[string]interface{}{}
+		setString := func(name string, field *string, value *string) {
+			if value != nil && *value != *field {
+				changes[name] = map[string]interface{}{"from": *field, "to": *value}
+				*field = *value
+			}
+		}
+		setString("base_currency", &settings.BaseCurrency, req.BaseCurrency)
+		setString("timezone", &settings.Timezone, req.Timezone)
+		setString("country", &settings.Country, req.Country)
+		setString("sku_prefix", &settings.SKUPrefix, req.SKUPrefix)
+		setString("order_number_prefix", &settings.OrderNumberPrefix, req.OrderNumberPrefix)
+		setString("unit_system", &settings.UnitSystem, req.UnitSystem)
+		if req.DefaultTaxRate != nil && *req.DefaultTaxRate != settings.DefaultTaxRate {
+			changes["default_tax_rate"] = map[string]interface{}{"from": settings.DefaultTaxRate, "to": *req.DefaultTaxRate}
+			settings.DefaultTaxRate = *req.DefaultTaxRate
+		}
+		if req.DefaultLowStockThreshold != nil && *req.DefaultLowStockThreshold != settings.DefaultLowStockThreshold {
+			changes["default_low_stock_threshold"] = map[string]interface{}{"from": settings.DefaultLowStockThreshold, "to": *req.DefaultLowStockThreshold}
+			settings.DefaultLowStockThreshold = *req.DefaultLowStockThreshold
+		}
+
+		if len(changes) == 0 {
+			return nil
+		}
+
+		settings.UpdatedBy = &user.ID
+		if err := tx.Save(&settings).Error; err != nil {
+			return err
+		}
+
+		return user.AddHistory(tx, "ORGANIZATION_SETTINGS_UPDATED", map[string]interface{}{
+			"organization_id": organization.ID,
+			"changes":         changes,
+		}, ipAddress)
+	})
+	if err != nil {
+		return nil, errors.New("failed to update organization settings")
+	}
+
+	response := mapper.OrganizationSettingsModelToDTO(settings)
+	return &response, nil
+}
+
+// organizationDB returns the database scoped to the organization, for requests
+// that name the organization in their path rather than through the active one
+func (ac *AuthController) organizationDB(organizationID uint) *gorm.DB {
+	return ac.DB.WithContext(tenant.WithOrganization(ac.DB.Statement.Context, organizationID))
+}
@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/ainventory/config"
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/mailer"
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ForgotPassword emails a single use reset link. It never reveals whether the
+// email belongs to an account.
+func (ac *AuthController) ForgotPassword(req dto.ForgotPasswordRequestDTO, ipAddress string) error {
+	var user models.User
+	result := ac.DB.Where("email = ?", strings.ToLower(req.Email)).First(&user)
+	if result.RowsAffected == 0 || user.Status != "active" {
+		return nil
+	}
+
+	token, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return errors.New("failed to generate reset token")
+	}
+
+	err = ac.DB.Transaction(func(tx *gorm.DB) error {
+		// Only the latest link works
+		err := tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Update("used_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+
+		resetToken := models.PasswordResetToken{
+			UserID:      user.ID,
+			TokenHash:   utils.HashToken(token),
+			ExpiresAt:   time.Now().Add(config.PasswordResetTTL()),
+			RequestedIP: ipAddress,
+		}
+		return tx.Create(&resetToken).Error
+	})
+	if err != nil {
+		return errors.New("failed to create reset token")
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", config.FrontendURL(), url.QueryEscape(token))
+	err = ac.Mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\nThe link expires in %s. If you did not request this, you can ignore this email.\n",
+			user.FirstName, link, config.PasswordResetTTL(),
+		),
+	})
+	if err != nil {
+		log.Printf("Failed to send password reset email to %s: %v", user.Email, err)
+		return errors.New("failed to send reset email")
+	}
+
+	user.AddHistory(ac.DB, "PASSWORD_RESET_REQUESTED", map[string]interface{}{}, ipAddress)
+
+	return nil
+}
+
+// ResetPassword sets a new password using an emailed token and logs out every session
+func (ac *AuthController) ResetPassword(req dto.ResetPasswordRequestDTO, ipAddress string) error {
+	return ac.DB.Transaction(func(tx *gorm.DB) error {
+		var resetToken models.PasswordResetToken
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", utils.HashToken(req.Token)).
+			First(&resetToken)
+		if result.Error != nil || resetToken.UsedAt != nil || time.Now().After(resetToken.ExpiresAt) {
+			return errors.New("invalid or expired reset link")
+		}
+
+		var user models.User
+		if err := tx.Where("id = ?", resetToken.UserID).First(&user).Error; err != nil {
+			return errors.New("invalid or expired reset link")
+		}
+
+		if err := tx.Model(&resetToken).Update("used_at", time.Now()).Error; err != nil {
+			return errors.New("failed to reset password")
+		}
+
+		if err := user.SetPassword(req.NewPassword); err != nil {
+			return errors.New("failed to reset password")
+		}
+		user.MustChangePassword = false
+		user.ApplyPasswordExpiry(tx)
+
+		// Proving access to the mailbox is enough to lift a brute force lock
+		user.AccountLocked = false
+		user.LockedAt = nil
+		user.FailedLoginAttempts = 0
+		user.LastFailedLoginAt = nil
+
+		if err := user.LogoutAllSessions(tx, "password_reset"); err != nil {
+			return errors.New("failed to reset password")
+		}
+
+		return user.AddHistory(tx, "PASSWORD_RESET", map[string]interface{}{}, ipAddress)
+	})
+}
+
+// ChangePassword replaces the password of a signed in user, logs out every other
+// session and returns a fresh token pair for the current client
+func (ac *AuthController) ChangePassword(user *models.User, req dto.ChangePasswordRequestDTO, ipAddress, userAgent string) (*dto.SignInResponseDTO, error) {
+	if !user.CheckPassword(req.OldPassword) {
+		return nil, errors.New("current password is incorrect")
+	}
+
+	if req.OldPassword == req.NewPassword {
+		return nil, errors.New("new password must be different from the current password")
+	}
+
+	var response *dto.SignInResponseDTO
+	err := ac.DB.Transaction(func(tx *gorm.DB) error {
+		if err := user.SetPassword(req.NewPassword); err != nil {
+			return errors.New("failed to change password")
+		}
+		user.MustChangePassword = false
+		user.ApplyPasswordExpiry(tx)
+
+		if err := user.LogoutAllSessions(tx, "password_change"); err != nil {
+			return errors.New("failed to change password")
+		}
+
+		if err := user.AddHistory(tx, "PASSWORD_CHANGED", map[string]interface{}{}, ipAddress); err != nil {
+			return errors.New("failed to change password")
+		}
+
+		var err error
+		response, err = ac.startSession(tx, *user, ipAddress, userAgent)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// RequirePasswordChange forces a user to choose a new password on their next request
+func (ac *AuthController) RequirePasswordChange(actor *models.User, userID uint, ipAddress string) error {
+	user, err := ac.findManagedUser(actor, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := ac.DB.Model(&user).Update("must_change_password", true).Error; err != nil {
+		return errors.New("failed to update user")
+	}
+
+	user.AddHistory(ac.DB, "PASSWORD_CHANGE_REQUIRED", map[string]interface{}{
+		"required_by": actor.ID,
+	}, ipAddress)
+
+	return nil
+}
@@ -0,0 +1,465 @@
+package controller
+
+import (
+	"errors"
+	"time"
+
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/mapper"
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/tenant"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrExplicitDeny is returned when granting a permission that is explicitly denied to the user
+var ErrExplicitDeny = errors.New("the permission is explicitly denied to this user")
+
+// templateDiff sorts the permissions of a template by what applying it does to the target
+type templateDiff struct {
+	Template       *models.PermissionTemplate
+	ToGrant        []models.Permission
+	AlreadyGranted []models.Permission
+	Denied         []models.Permission // Explicit denies of the target user, applying keeps them
+}
+
+func (ac *AuthController) PermissionTemplateList() ([]dto.PermissionTemplateResponseDTO, error) {
+	var templates []models.PermissionTemplate
+	if err := ac.DB.Preload("Permissions").Order("name ASC").Find(&templates).Error; err != nil {
+		return nil, errors.New("error retrieving templates")
+	}
+
+	responseDTOs := make([]dto.PermissionTemplateResponseDTO, 0, len(templates))
+	for _, template := range templates {
+		responseDTOs = append(responseDTOs, *mapper.PermissionTemplateModelToDTO(template, ac.linkedRoleIDs(ac.DB, template.ID)))
+	}
+
+	return responseDTOs, nil
+}
+
+func (ac *AuthController) PermissionTemplateDetail(templateID uint) (*dto.PermissionTemplateResponseDTO, error) {
+	var template models.PermissionTemplate
+	result := ac.DB.Preload("Permissions").Where("id = ?", templateID).First(&template)
+	if result.RowsAffected == 0 {
+		return nil, errors.New("template not found")
+	}
+
+	return mapper.PermissionTemplateModelToDTO(template, ac.linkedRoleIDs(ac.DB, template.ID)), nil
+}
+
+func (ac *AuthController) CreatePermissionTemplate(user *models.User, request dto.PermissionTemplateRequestDTO) (*dto.PermissionTemplateResponseDTO, error) {
+	request.Normalize()
+
+	if err := ac.checkRoleAuthority(user); err != nil {
+		return nil, err
+	}
+
+	var existingTemplate models.PermissionTemplate
+	result := ac.DB.Where("name = ?", request.Name).First(&existingTemplate)
+	if result.RowsAffected > 0 {
+		return nil, errors.New("template name already exists")
+	}
+
+	permissionIDs := uniqueUints(request.PermissionIDs)
+	if !ac.permissionsExist(permissionIDs) {
+		return nil, errors.New("permission not found")
+	}
+
+	template := models.PermissionTemplate{
+		Name:        request.Name,
+		Description: request.Description,
+		Category:    request.Category,
+		IsActive:    request.IsActive == nil || *request.IsActive,
+	}
+
+	err := ac.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&template).Error; err != nil {
+			return err
+		}
+		return setTemplatePermissions(tx, template.ID, permissionIDs)
+	})
+	if err != nil {
+		return nil, errors.New("failed to create template")
+	}
+
+	return ac.PermissionTemplateDetail(template.ID)
+}
+
+// UpdatePermissionTemplate changes a template. Permissions added to or removed from
+// it are granted to or revoked from every role linked to the template.
+func (ac *AuthController) UpdatePermissionTemplate(user *models.User, templateID uint, request dto.PermissionTemplateRequestDTO) (*dto.PermissionTemplateResponseDTO, error) {
+	request.Normalize()
+
+	if err := ac.checkRoleAuthority(user); err != nil {
+		return nil, err
+	}
+
+	var template models.PermissionTemplate
+	result := ac.DB.Where("id = ?", templateID).First(&template)
+	if result.RowsAffected == 0 {
+		return nil, errors.New("template not found")
+	}
+
+	var existingTemplate models.PermissionTemplate
+	result = ac.DB.Where("name = ? AND id != ?", request.Name, templateID).First(&existingTemplate)
+	if result.RowsAffected > 0 {
+		return nil, errors.New("template name already exists")
+	}
+
+	permissionIDs := uniqueUints(request.PermissionIDs)
+	if !ac.permissionsExist(permissionIDs) {
+		return nil, errors.New("permission not found")
+	}
+
+	added, removed := diffUints(template.PermissionIDs(ac.DB), permissionIDs)
+
+	template.Name = request.Name
+	template.Description = request.Description
+	template.Category = request.Category
+	if request.IsActive != nil {
+		template.IsActive = *request.IsActive
+	}
+
+	err := ac.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&template).Error; err != nil {
+			return err
+		}
+
+		if err := setTemplatePermissions(tx, template.ID, permissionIDs); err != nil {
+			return err
+		}
+
+		for _, roleID := range ac.linkedRoleIDs(tx, template.ID) {
+			if err := grantRolePermissions(tx, roleID, added, user.ID); err != nil {
+				return err
+			}
+			if len(removed) > 0 {
+				err := tx.Where("role_id = ? AND permission_id IN ?", roleID, removed).Delete(&models.RolePermission{}).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.New("failed to update template")
+	}
+
+	return ac.PermissionTemplateDetail(template.ID)
+}
+
+// DeletePermissionTemplate removes a template, linked roles keep their permissions
+func (ac *AuthController) DeletePermissionTemplate(user *models.User, templateID uint) error {
+	if err := ac.checkRoleAuthority(user); err != nil {
+		return err
+	}
+
+	var template models.PermissionTemplate
+	result := ac.DB.Where("id = ?", templateID).First(&template)
+	if result.RowsAffected == 0 {
+		return errors.New("template not found")
+	}
+
+	err := ac.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Role{}).Where("template_id = ?", template.ID).Update("template_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("template_id = ?", template.ID).Delete(&models.TemplatePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&template).Error
+	})
+	if err != nil {
+		return errors.New("failed to delete template")
+	}
+
+	return nil
+}
+
+// PreviewPermissionTemplate shows which permissions applying the template would
+// grant. The target is checked like ApplyPermissionTemplate does, the preview
+// reveals its current grants.
+func (ac *AuthController) PreviewPermissionTemplate(user *models.User, templateID uint, request dto.PermissionTemplateApplyRequestDTO) (*dto.PermissionTemplateDiffResponseDTO, error) {
+	diff, err := ac.permissionTemplateDiff(ac.DB, templateID, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ac.checkTemplateTarget(user, request); err != nil {
+		return nil, err
+	}
+
+	return templateDiffToDTO(diff, request, false), nil
+}
+
+// ApplyPermissionTemplate grants the template to a role, or to a user as direct
+// UserPermission rows of the active organization, in one transaction
+func (ac *AuthController) ApplyPermissionTemplate(user *models.User, templateID uint, request dto.PermissionTemplateApplyRequestDTO, ipAddress string) (*dto.PermissionTemplateDiffResponseDTO, error) {
+	var response *dto.PermissionTemplateDiffResponseDTO
+
+	err := ac.DB.Transaction(func(tx *gorm.DB) error {
+		diff, err := ac.permissionTemplateDiff(tx, templateID, request)
+		if err != nil {
+			return err
+		}
+		template := diff.Template
+
+		permissionIDs := make([]uint, len(diff.ToGrant))
+		for i, permission := range diff.ToGrant {
+			permissionIDs[i] = permission.ID
+		}
+
+		if err := ac.checkTemplateTarget(user, request); err != nil {
+			return err
+		}
+		if err := ac.checkGrantAuthority(user, permissionIDs); err != nil {
+			return err
+		}
+
+		if request.TargetType == "role" {
+			if err := grantRolePermissions(tx, request.TargetID, permissionIDs, user.ID); err != nil {
+				return errors.New("failed to apply template")
+			}
+			if request.Link {
+				err := tx.Model(&models.Role{}).Where("id = ?", request.TargetID).Update("template_id", template.ID).Error
+				if err != nil {
+					return errors.New("failed to apply template")
+				}
+			}
+		} else {
+			reason := request.Reason
+			if reason == "" {
+				reason = "Template: " + template.Name
+			}
+			// checkTemplateTarget made sure there is an active organization
+			organizationID, _ := tenant.OrganizationID(tx.Statement.Context)
+			for _, permissionID := range permissionIDs {
+				if err := grantUserPermission(tx, organizationID, request.TargetID, permissionID, user.ID, request.ExpiresAt, reason); err != nil {
+					return errors.New("failed to apply template")
+				}
+			}
+
+			target := models.User{ID: request.TargetID}
+			target.AddHistory(tx, "PERMISSION_TEMPLATE_APPLIED", map[string]interface{}{
+				"template_id":    template.ID,
+				"permission_ids": permissionIDs,
+				"applied_by":     user.ID,
+			}, ipAddress)
+		}
+
+		response = templateDiffToDTO(diff, request, true)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (ac *AuthController) permissionTemplateDiff(tx *gorm.DB, templateID uint, request dto.PermissionTemplateApplyRequestDTO) (*templateDiff, error) {
+	var template models.PermissionTemplate
+	result := tx.Preload("Permissions").Where("id = ?", templateID).First(&template)
+	if result.RowsAffected == 0 {
+		return nil, errors.New("template not found")
+	}
+
+	if !template.IsActive {
+		return nil, errors.New("template is not active")
+	}
+
+	var currentIDs, deniedIDs []uint
+	switch request.TargetType {
+	case "role":
+		var role models.Role
+		if tx.Where("id = ?", request.TargetID).First(&role).RowsAffected == 0 {
+			return nil, errors.New("role not found")
+		}
+		currentIDs = role.PermissionIDs(tx)
+	case "user":
+		if request.Link {
+			return nil, errors.New("only roles can be linked to a template")
+		}
+		var target models.User
+		if tx.Where("id = ?", request.TargetID).First(&target).RowsAffected == 0 {
+			return nil, errors.New("user not found")
+		}
+		organizationID, _ := tenant.OrganizationID(tx.Statement.Context)
+		currentIDs = target.DirectPermissionIDs(tx)
+		tx.Model(&models.UserPermission{}).
+			Where("organization_id = ? AND user_id = ? AND is_granted = ?", organizationID, target.ID, false).
+			Pluck("permission_id", &deniedIDs)
+	default:
+		return nil, errors.New("target type must be role or user")
+	}
+
+	current := make(map[uint]bool, len(currentIDs))
+	for _, id := range currentIDs {
+		current[id] = true
+	}
+	denied := make(map[uint]bool, len(deniedIDs))
+	for _, id := range deniedIDs {
+		denied[id] = true
+	}
+
+	diff := &templateDiff{Template: &template}
+	for _, permission := range template.Permissions {
+		switch {
+		case denied[permission.ID]:
+			diff.Denied = append(diff.Denied, permission)
+		case current[permission.ID]:
+			diff.AlreadyGranted = append(diff.AlreadyGranted, permission)
+		default:
+			diff.ToGrant = append(diff.ToGrant, permission)
+		}
+	}
+
+	return diff, nil
+}
+
+// checkTemplateTarget refuses to apply a template to a role or user the actor may
+// not manage. Users have to belong to the actor's active organization, their
+// grants apply there.
+func (ac *AuthController) checkTemplateTarget(user *models.User, request dto.PermissionTemplateApplyRequestDTO) error {
+	if request.TargetType == "role" {
+		var role models.Role
+		if ac.DB.Where("id = ?", request.TargetID).First(&role).RowsAffected == 0 {
+			return errors.New("role not found")
+		}
+		return ac.checkRoleAuthority(user)
+	}
+
+	target, err := ac.findManagedUser(user, request.TargetID)
+	if err != nil {
+		return err
+	}
+	_, err = ac.grantOrganization(target)
+	return err
+}
+
+func (ac *AuthController) linkedRoleIDs(tx *gorm.DB, templateID uint) []uint {
+	var ids []uint
+	tx.Model(&models.Role{}).Where("template_id = ?", templateID).Pluck("id", &ids)
+	return ids
+}
+
+func (ac *AuthController) permissionsExist(permissionIDs []uint) bool {
+	var count int64
+	ac.DB.Model(&models.Permission{}).Where("id IN ?", permissionIDs).Count(&count)
+	return int(count) == len(permissionIDs)
+}
+
+func setTemplatePermissions(tx *gorm.DB, templateID uint, permissionIDs []uint) error {
+	if err := tx.Where("template_id = ?", templateID).Delete(&models.TemplatePermission{}).Error; err != nil {
+		return err
+	}
+
+	rows := make([]models.TemplatePermission, len(permissionIDs))
+	for i, permissionID := range permissionIDs {
+		rows[i] = models.TemplatePermission{TemplateID: templateID, PermissionID: permissionID}
+	}
+	return tx.Create(&rows).Error
+}
+
+// grantRolePermissions adds permissions to a role, existing grants are kept as they are
+func grantRolePermissions(tx *gorm.DB, roleID uint, permissionIDs []uint, grantedBy uint) error {
+	if len(permissionIDs) == 0 {
+		return nil
+	}
+
+	grants := make([]models.RolePermission, len(permissionIDs))
+	for i, permissionID := range permissionIDs {
+		grants[i] = models.RolePermission{RoleID: roleID, PermissionID: permissionID, GrantedBy: grantedBy}
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&grants).Error
+}
+
+// grantUserPermission grants a permission directly to a user within one organization,
+// turning an expired grant back on. Explicit denies are refused, and a grant still
+// in effect keeps the later of both expiries so it is never shortened.
+func grantUserPermission(tx *gorm.DB, organizationID, userID, permissionID, grantedBy uint, expiresAt *time.Time, reason string) error {
+	var grant models.UserPermission
+	result := tx.Where("organization_id = ? AND user_id = ? AND permission_id = ?", organizationID, userID, permissionID).First(&grant)
+	if result.RowsAffected > 0 {
+		if !grant.IsGranted {
+			return ErrExplicitDeny
+		}
+		if grant.ExpiresAt == nil || grant.ExpiresAt.After(time.Now()) {
+			expiresAt = laterExpiry(grant.ExpiresAt, expiresAt)
+		}
+
+		return tx.Model(&grant).Updates(map[string]interface{}{
+			"is_granted": true,
+			"granted_by": grantedBy,
+			"granted_at": time.Now(),
+			"expires_at": expiresAt,
+			"reason":     reason,
+		}).Error
+	}
+
+	return tx.Create(&models.UserPermission{
+		OrganizationID: organizationID,
+		UserID:         userID,
+		PermissionID:   permissionID,
+		IsGranted:      true,
+		GrantedBy:      grantedBy,
+		ExpiresAt:      expiresAt,
+		Reason:         reason,
+	}).Error
+}
+
+// laterExpiry returns the expiry that lasts longer, nil being permanent
+func laterExpiry(a, b *time.Time) *time.Time {
+	if a == nil || b == nil {
+		return nil
+	}
+	if a.After(*b) {
+		return a
+	}
+	return b
+}
+
+func templateDiffToDTO(diff *templateDiff, request dto.PermissionTemplateApplyRequestDTO, applied bool) *dto.PermissionTemplateDiffResponseDTO {
+	response := &dto.PermissionTemplateDiffResponseDTO{
+		TargetType:     request.TargetType,
+		TargetID:       request.TargetID,
+		ToGrant:        make([]dto.PermissionResponseDTO, len(diff.ToGrant)),
+		AlreadyGranted: make([]dto.PermissionResponseDTO, len(diff.AlreadyGranted)),
+		Denied:         make([]dto.PermissionResponseDTO, len(diff.Denied)),
+		Linked:         request.TargetType == "role" && request.Link,
+		Applied:        applied,
+	}
+	for i, permission := range diff.ToGrant {
+		response.ToGrant[i] = mapper.PermissionModelToDTO(permission)
+	}
+	for i, permission := range diff.AlreadyGranted {
+		response.AlreadyGranted[i] = mapper.PermissionModelToDTO(permission)
+	}
+	for i, permission := range diff.Denied {
+		response.Denied[i] = mapper.PermissionModelToDTO(permission)
+	}
+	return response
+}
+
+// diffUints returns the values only in next (added) and only in previous (removed)
+func diffUints(previous, next []uint) (added, removed []uint) {
+	inPrevious := make(map[uint]bool, len(previous))
+	for _, value := range previous {
+		inPrevious[value] = true
+	}
+	inNext := make(map[uint]bool, len(next))
+	for _, value := range next {
+		inNext[value] = true
+		if !inPrevious[value] {
+			added = append(added, value)
+		}
+	}
+	for _, value := range previous {
+		if !inNext[value] {
+			removed = append(removed, value)
+		}
+	}
+	return added, removed
+}
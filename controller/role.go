@@ -0,0 +1,282 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/mapper"
+	"github.com/farhapartex/ainventory/models"
+	"gorm.io/gorm"
+)
+
+func (ac *AuthController) RoleList() ([]dto.RoleResponseDTO, error) {
+	var roles []models.Role
+	if err := ac.DB.Order("level DESC, name ASC").Find(&roles).Error; err != nil {
+		return nil, errors.New("error retrieving roles")
+	}
+
+	responseDTOs := make([]dto.RoleResponseDTO, 0, len(roles))
+	for _, role := range roles {
+		responseDTOs = append(responseDTOs, *mapper.RoleModelToDTO(role))
+	}
+
+	return responseDTOs, nil
+}
+
+// RoleDetail returns a role together with its permissions grouped by module
+func (ac *AuthController) RoleDetail(roleID uint) (*dto.RoleResponseDTO, error) {
+	var role models.Role
+	result := ac.DB.Where("id = ?", roleID).First(&role)
+	if result.RowsAffected == 0 {
+		return nil, errors.New("role not found")
+	}
+
+	grouped, err := role.GetPermissionsByModule(ac.DB)
+	if err != nil {
+		return nil, errors.New("error retrieving role permissions")
+	}
+
+	response := mapper.RoleModelToDTO(role)
+	response.Permissions = mapper.PermissionsByModuleToDTO(grouped, ac.permissionModuleNames())
+
+	return response, nil
+}
+
+func (ac *AuthController) CreateRole(user *models.User, request dto.RoleRequestDTO) (*dto.RoleResponseDTO, error) {
+	request.Normalize()
+
+	if err := ac.checkRoleAuthority(user); err != nil {
+		return nil, err
+	}
+
+	var existingRole models.Role
+	result := ac.DB.Unscoped().Where("name = ?", request.Name).First(&existingRole)
+	if result.RowsAffected > 0 {
+		return nil, errors.New("role name already exists")
+	}
+
+	newRow := mapper.RoleDTOToModel(request)
+	newRow.CreatedBy = user.ID
+	if err := ac.DB.Create(newRow).Error; err != nil {
+		return nil, errors.New("failed to create role")
+	}
+
+	return mapper.RoleModelToDTO(*newRow), nil
+}
+
+func (ac *AuthController) UpdateRole(user *models.User, roleID uint, request dto.RoleRequestDTO) (*dto.RoleResponseDTO, error) {
+	request.Normalize()
+
+	var role models.Role
+	result := ac.DB.Where("id = ?", roleID).First(&role)
+	if result.RowsAffected == 0 {
+		return nil, errors.New("role not found")
+	}
+
+	if err := ac.checkRoleAuthority(user); err != nil {
+		return nil, err
+	}
+
+	if role.IsSystem && role.Name != request.Name {
+		return nil, errors.New("system roles cannot be renamed")
+	}
+
+	var existingRole models.Role
+	result = ac.DB.Unscoped().Where("name = ? AND id != ?", request.Name, roleID).First(&existingRole)
+	if result.RowsAffected > 0 {
+		return nil, errors.New("role name already exists")
+	}
+
+	updateData := mapper.RoleDTOToModel(request)
+	role.Name = updateData.Name
+	role.DisplayName = updateData.DisplayName
+	role.Description = updateData.Description
+	role.Level = updateData.Level
+	role.Color = updateData.Color
+	if request.IsDefault != nil {
+		role.IsDefault = updateData.IsDefault
+	}
+	if request.IsActive != nil {
+		role.IsActive = updateData.IsActive
+	}
+
+	if err := ac.DB.Save(&role).Error; err != nil {
+		return nil, errors.New("failed to update role")
+	}
+
+	return mapper.RoleModelToDTO(role), nil
+}
+
+// DeleteRole removes a role. System roles and roles that still have users are
+// refused with models.ErrSystemRole and models.ErrRoleInUse.
+func (ac *AuthController) DeleteRole(user *models.User, roleID uint) error {
+	var role models.Role
+	result := ac.DB.Where("id = ?", roleID).First(&role)
+	if result.RowsAffected == 0 {
+		return errors.New("role not found")
+	}
+
+	if err := ac.checkRoleAuthority(user); err != nil {
+		return err
+	}
+
+	err := ac.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&role).Error; err != nil {
+			return err
+		}
+		return tx.Where("role_id = ?", role.ID).Delete(&models.RolePermission{}).Error
+	})
+	if errors.Is(err, models.ErrSystemRole) || errors.Is(err, models.ErrRoleInUse) {
+		return err
+	}
+	if err != nil {
+		return errors.New("failed to delete role")
+	}
+
+	return nil
+}
+
+// PermissionList returns every active permission grouped by module
+func (ac *AuthController) PermissionList() ([]dto.PermissionModuleResponseDTO, error) {
+	var permissions []models.Permission
+	if err := ac.DB.Where("is_active = ?", true).Find(&permissions).Error; err != nil {
+		return nil, errors.New("error retrieving permissions")
+	}
+
+	grouped := make(map[string][]models.Permission)
+	for _, permission := range permissions {
+		grouped[permission.Module] = append(grouped[permission.Module], permission)
+	}
+
+	return mapper.PermissionsByModuleToDTO(grouped, ac.permissionModuleNames()), nil
+}
+
+func (ac *AuthController) GrantRolePermissions(user *models.User, roleID uint, request dto.RolePermissionGrantRequestDTO) (*dto.RoleResponseDTO, error) {
+	var role models.Role
+	result := ac.DB.Where("id = ?", roleID).First(&role)
+	if result.RowsAffected == 0 {
+		return nil, errors.New("role not found")
+	}
+
+	if err := ac.checkRoleAuthority(user); err != nil {
+		return nil, err
+	}
+
+	if !ac.permissionsExist(uniqueUints(request.PermissionIDs)) {
+		return nil, errors.New("permission not found")
+	}
+
+	// Permissions the role already has keep their original grant
+	err := grantRolePermissions(ac.DB, role.ID, uniqueUints(request.PermissionIDs), user.ID)
+	if err != nil {
+		return nil, errors.New("failed to grant permissions")
+	}
+
+	return ac.RoleDetail(role.ID)
+}
+
+func (ac *AuthController) RevokeRolePermission(user *models.User, roleID, permissionID uint) error {
+	var role models.Role
+	if ac.DB.Where("id = ?", roleID).First(&role).RowsAffected == 0 {
+		return errors.New("role not found")
+	}
+
+	if err := ac.checkRoleAuthority(user); err != nil {
+		return err
+	}
+
+	result := ac.DB.Where("role_id = ? AND permission_id = ?", roleID, permissionID).Delete(&models.RolePermission{})
+	if result.Error != nil {
+		return errors.New("failed to revoke permission")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("role does not have this permission")
+	}
+
+	return nil
+}
+
+// AssignUserRole changes the role of a user within the actor's active organization,
+// a nil role removes it
+func (ac *AuthController) AssignUserRole(actor *models.User, userID uint, request dto.UserRoleAssignRequestDTO, ipAddress string) error {
+	if userID == actor.ID {
+		return fmt.Errorf("%w: you cannot change your own role", ErrPrivilegeEscalation)
+	}
+
+	user, err := ac.findManagedUser(actor, userID)
+	if err != nil {
+		return err
+	}
+
+	organizationID, err := ac.grantOrganization(user)
+	if err != nil {
+		return err
+	}
+
+	if request.RoleID != nil {
+		var role models.Role
+		result := ac.DB.Where("id = ? AND is_active = ?", *request.RoleID, true).First(&role)
+		if result.RowsAffected == 0 {
+			return errors.New("role not found")
+		}
+
+		if err := ac.checkRoleLevel(actor, role.Level); err != nil {
+			return err
+		}
+
+		// A role hands out all of its permissions, the actor must hold every one
+		var permissionIDs []uint
+		ac.DB.Model(&models.RolePermission{}).Where("role_id = ?", role.ID).Pluck("permission_id", &permissionIDs)
+		if err := ac.checkGrantAuthority(actor, permissionIDs); err != nil {
+			return err
+		}
+	}
+
+	err = ac.DB.Transaction(func(tx *gorm.DB) error {
+		var membership models.OrganizationMember
+		if err := tx.Where("organization_id = ? AND user_id = ?", organizationID, user.ID).First(&membership).Error; err != nil {
+			return err
+		}
+
+		previousRoleID := membership.RoleID
+		if err := membership.AssignRole(tx, request.RoleID); err != nil {
+			return err
+		}
+
+		return user.AddHistory(tx, "ROLE_ASSIGNED", map[string]interface{}{
+			"organization_id":  organizationID,
+			"previous_role_id": previousRoleID,
+			"role_id":          request.RoleID,
+			"assigned_by":      actor.ID,
+		}, ipAddress)
+	})
+	if err != nil {
+		return errors.New("failed to assign role")
+	}
+
+	return nil
+}
+
+func (ac *AuthController) permissionModuleNames() map[string]string {
+	var modules []models.PermissionModule
+	ac.DB.Find(&modules)
+
+	names := make(map[string]string, len(modules))
+	for _, module := range modules {
+		names[module.Name] = module.DisplayName
+	}
+	return names
+}
+
+func uniqueUints(values []uint) []uint {
+	seen := make(map[uint]bool, len(values))
+	unique := make([]uint, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/tenant"
+)
+
+// ErrPrivilegeEscalation is returned when a change would give someone more access
+// than the user making it has
+var ErrPrivilegeEscalation = errors.New("privilege escalation denied")
+
+// checkRoleLevel refuses to work with a role level above the actor's own
+func (ac *AuthController) checkRoleLevel(actor *models.User, level int) error {
+	if level > actor.RoleLevel(ac.DB) {
+		return fmt.Errorf("%w: you cannot manage roles above your own level", ErrPrivilegeEscalation)
+	}
+	return nil
+}
+
+// checkRoleAuthority refuses changes to roles and permission templates unless the
+// actor is a superuser. Both are shared by every organization, a change reaches the
+// members of all of them.
+func (ac *AuthController) checkRoleAuthority(actor *models.User) error {
+	if !actor.IsSuperuser {
+		return fmt.Errorf("%w: roles and templates are shared by every organization and can only be changed by a superuser", ErrPrivilegeEscalation)
+	}
+	return nil
+}
+
+// checkUserAuthority refuses changes to users whose role in the active organization
+// ranks above the actor's
+func (ac *AuthController) checkUserAuthority(actor *models.User, target *models.User) error {
+	if target.IsSuperuser && !actor.IsSuperuser {
+		return fmt.Errorf("%w: only superusers can manage superusers", ErrPrivilegeEscalation)
+	}
+	if target.RoleLevel(ac.DB) > actor.RoleLevel(ac.DB) {
+		return fmt.Errorf("%w: you cannot manage users above your own level", ErrPrivilegeEscalation)
+	}
+	return nil
+}
+
+// checkSameOrganization refuses to manage users outside the actor's active
+// organization. Superusers manage the users of every organization.
+func (ac *AuthController) checkSameOrganization(actor *models.User, target *models.User) error {
+	if actor.IsSuperuser {
+		return nil
+	}
+
+	organizationID, ok := tenant.OrganizationID(ac.DB.Statement.Context)
+	if !ok {
+		return fmt.Errorf("%w: select the organization of the user to manage", ErrOrganizationAccess)
+	}
+
+	var count int64
+	ac.DB.Model(&models.OrganizationMember{}).Where("organization_id = ? AND user_id = ?", organizationID, target.ID).Count(&count)
+	if count == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// findManagedUser loads a user the actor may administer: a member of the actor's
+// active organization whose role does not rank above the actor's
+func (ac *AuthController) findManagedUser(actor *models.User, userID uint) (*models.User, error) {
+	var user models.User
+	result := ac.DB.Where("id = ?", userID).First(&user)
+	if result.RowsAffected == 0 {
+		return nil, errors.New("user not found")
+	}
+
+	if err := ac.checkSameOrganization(actor, &user); err != nil {
+		return nil, err
+	}
+	if err := ac.checkUserAuthority(actor, &user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// checkGrantAuthority refuses to hand out permissions the actor does not hold in the
+// active organization
+func (ac *AuthController) checkGrantAuthority(actor *models.User, permissionIDs []uint) error {
+	if actor.IsSuperuser || len(permissionIDs) == 0 {
+		return nil
+	}
+	if _, ok := tenant.OrganizationID(ac.DB.Statement.Context); !ok {
+		return fmt.Errorf("%w: select the organization whose permissions you hand out", ErrOrganizationAccess)
+	}
+
+	var permissions []models.Permission
+	ac.DB.Where("id IN ?", permissionIDs).Find(&permissions)
+	for _, permission := range permissions {
+		if !actor.HasPermission(ac.DB, permission.Name) {
+			return fmt.Errorf("%w: you cannot grant %s without holding it", ErrPrivilegeEscalation, permission.Name)
+		}
+	}
+	return nil
+}
+
+// grantOrganization returns the active organization, the one direct grants and role
+// assignments of the target user apply in. The target has to be a member of it.
+func (ac *AuthController) grantOrganization(target *models.User) (uint, error) {
+	organizationID, ok := tenant.OrganizationID(ac.DB.Statement.Context)
+	if !ok {
+		return 0, fmt.Errorf("%w: select the organization the permissions apply in", ErrOrganizationAccess)
+	}
+
+	var count int64
+	ac.DB.Model(&models.OrganizationMember{}).Where("organization_id = ? AND user_id = ?", organizationID, target.ID).Count(&count)
+	if count == 0 {
+		return 0, errors.New("user not found")
+	}
+	return organizationID, nil
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"errors"
+	"time"
+
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/mapper"
+	"github.com/farhapartex/ainventory/models"
+)
+
+// UserSessionList returns the active sessions of the user, newest activity first
+func (ac *AuthController) UserSessionList(user *models.User, currentFamilyID string) ([]dto.UserSessionResponseDTO, error) {
+	var sessions []models.UserSession
+	err := ac.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", user.ID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, errors.New("error retrieving sessions")
+	}
+
+	responseDTOs := make([]dto.UserSessionResponseDTO, 0, len(sessions))
+	for _, session := range sessions {
+		responseDTOs = append(responseDTOs, mapper.UserSessionModelToDTO(session, currentFamilyID))
+	}
+
+	return responseDTOs, nil
+}
+
+// RevokeUserSession signs one of the user's devices out
+func (ac *AuthController) RevokeUserSession(user *models.User, sessionID uint, ipAddress string) error {
+	var session models.UserSession
+	result := ac.DB.Where("id = ? AND user_id = ?", sessionID, user.ID).First(&session)
+	if result.RowsAffected == 0 {
+		return errors.New("session not found")
+	}
+
+	if session.RevokedAt != nil {
+		return nil
+	}
+
+	if err := models.RevokeSession(ac.DB, session.FamilyID); err != nil {
+		return errors.New("failed to revoke session")
+	}
+
+	user.AddHistory(ac.DB, "SESSION_REVOKED", map[string]interface{}{
+		"session_id": session.ID,
+		"user_agent": session.UserAgent,
+	}, ipAddress)
+
+	return nil
+}
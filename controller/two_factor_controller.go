@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"errors"
+	"time"
+
+	"github.com/farhapartex/ainventory/config"
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/utils"
+)
+
+const backupCodeCount = 10
+
+// SetupTwoFactor generates a new TOTP secret. Two factor stays disabled until
+// the user proves the authenticator works with ConfirmTwoFactor.
+func (ac *AuthController) SetupTwoFactor(user *models.User) (*dto.TwoFactorSetupResponseDTO, error) {
+	if user.TwoFactorEnabled {
+		return nil, errors.New("two factor authentication is already enabled")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, errors.New("failed to generate secret")
+	}
+
+	user.TwoFactorSecret = secret
+	user.TwoFactorLastCounter = 0
+	if err := ac.DB.Save(user).Error; err != nil {
+		return nil, errors.New("failed to save secret")
+	}
+
+	return &dto.TwoFactorSetupResponseDTO{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(secret, user.Email, config.TOTPIssuer()),
+	}, nil
+}
+
+// ConfirmTwoFactor enables two factor once a valid code is presented and
+// returns the backup codes, which are shown only this one time
+func (ac *AuthController) ConfirmTwoFactor(user *models.User, req dto.TwoFactorCodeRequestDTO, ipAddress string) (*dto.TwoFactorBackupCodesResponseDTO, error) {
+	if user.TwoFactorEnabled {
+		return nil, errors.New("two factor authentication is already enabled")
+	}
+
+	if user.TwoFactorSecret == "" {
+		return nil, errors.New("two factor setup has not been started")
+	}
+
+	counter, ok := utils.ValidateTOTP(user.TwoFactorSecret, req.Code, user.TwoFactorLastCounter, time.Now())
+	if !ok {
+		return nil, errors.New("invalid verification code")
+	}
+
+	codes, err := utils.GenerateBackupCodes(backupCodeCount)
+	if err != nil {
+		return nil, errors.New("failed to generate backup codes")
+	}
+
+	if err := user.SetBackupCodes(codes); err != nil {
+		return nil, errors.New("failed to generate backup codes")
+	}
+	user.TwoFactorEnabled = true
+	user.TwoFactorLastCounter = counter
+
+	if err := ac.DB.Save(user).Error; err != nil {
+		return nil, errors.New("failed to enable two factor authentication")
+	}
+
+	user.AddHistory(ac.DB, "TWO_FACTOR_ENABLED", map[string]interface{}{}, ipAddress)
+
+	return &dto.TwoFactorBackupCodesResponseDTO{
+		BackupCodes: codes,
+	}, nil
+}
+
+// DisableTwoFactor turns two factor off after checking both the password and a code
+func (ac *AuthController) DisableTwoFactor(user *models.User, req dto.TwoFactorDisableRequestDTO, ipAddress string) error {
+	if !user.TwoFactorEnabled {
+		return errors.New("two factor authentication is not enabled")
+	}
+
+	if !user.CheckPassword(req.Password) {
+		return errors.New("password is incorrect")
+	}
+
+	if !ac.checkSecondFactor(user, req.Code, req.Code) {
+		return errors.New("invalid verification code")
+	}
+
+	if err := user.DisableTwoFactor(ac.DB); err != nil {
+		return errors.New("failed to disable two factor authentication")
+	}
+
+	user.AddHistory(ac.DB, "TWO_FACTOR_DISABLED", map[string]interface{}{}, ipAddress)
+
+	return nil
+}
+
+// VerifyTwoFactor completes a sign in started by SignIn for users with two factor enabled
+func (ac *AuthController) VerifyTwoFactor(req dto.TwoFactorVerifyRequestDTO, ipAddress, userAgent string) (*dto.SignInResponseDTO, error) {
+	claims, err := utils.ValidateJWT(req.MFAToken)
+	if err != nil || claims.TokenType != "mfa_pending" {
+		return nil, errors.New("invalid or expired sign in attempt, please sign in again")
+	}
+
+	var user models.User
+	result := ac.DB.Where("id = ?", claims.UserID).First(&user)
+	if result.RowsAffected == 0 || claims.TokenVersion != user.TokenVersion || user.IsTokenBlacklisted(ac.DB, claims.ID) {
+		return nil, errors.New("invalid or expired sign in attempt, please sign in again")
+	}
+
+	if user.AccountLocked && !user.IsLockExpired(config.LoginLockoutDuration()) {
+		return nil, ErrAccountLocked
+	}
+
+	if !user.CanLogin() || !user.TwoFactorEnabled {
+		return nil, errors.New("Permission denied to login")
+	}
+
+	if !ac.checkSecondFactor(&user, req.Code, req.BackupCode) {
+		user.IncrementFailedLoginAttempts(ac.DB, config.LoginMaxFailedAttempts(), config.LoginFailureWindow())
+		user.AddHistory(ac.DB, "TWO_FACTOR_FAILED", map[string]interface{}{
+			"failed_attempts": user.FailedLoginAttempts,
+		}, ipAddress)
+
+		if user.AccountLocked {
+			return nil, ErrAccountLocked
+		}
+		return nil, errors.New("invalid verification code")
+	}
+
+	// The pending token is single use
+	user.BlacklistToken(ac.DB, claims.ID, utils.HashToken(req.MFAToken), claims.ExpiresAt.Time, "mfa_completed")
+
+	return ac.completeSignIn(&user, ipAddress, userAgent)
+}
+
+// ResetTwoFactor lets an administrator remove two factor from a user who lost their device
+func (ac *AuthController) ResetTwoFactor(actor *models.User, userID uint, ipAddress string) error {
+	user, err := ac.findManagedUser(actor, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := user.DisableTwoFactor(ac.DB); err != nil {
+		return errors.New("failed to reset two factor authentication")
+	}
+
+	user.AddHistory(ac.DB, "TWO_FACTOR_RESET", map[string]interface{}{
+		"reset_by": actor.ID,
+	}, ipAddress)
+
+	return nil
+}
+
+// checkSecondFactor accepts either a current TOTP code or an unused backup code
+func (ac *AuthController) checkSecondFactor(user *models.User, code, backupCode string) bool {
+	if code != "" {
+		counter, ok := utils.ValidateTOTP(user.TwoFactorSecret, code, user.TwoFactorLastCounter, time.Now())
+		if ok {
+			user.TwoFactorLastCounter = counter
+			return ac.DB.Model(user).Update("two_factor_last_counter", counter).Error == nil
+		}
+	}
+
+	if backupCode != "" {
+		return user.UseBackupCode(ac.DB, backupCode)
+	}
+
+	return false
+}
@@ -12,7 +12,7 @@ import (
 func (ac *AuthController) UserProfile(userId uint) (*dto.UserMeResponseDTO, error) {
 	var user models.User
 
-	result := ac.DB.Where("id = ?", userId).Preload("Organizations").First(&user)
+	result := ac.DB.Where("id = ?", userId).Preload("Memberships.Organization").First(&user)
 
 	if result.RowsAffected == 0 {
 		return nil, errors.New("user not found")
@@ -21,6 +21,8 @@ func (ac *AuthController) UserProfile(userId uint) (*dto.UserMeResponseDTO, erro
 	return mapper.UserModelToUserProfileDTO(&user), nil
 }
 
+// UserOnboard completes the profile of a new user and creates their organization.
+// They become its owner, which grants them every permission within it.
 func (ac *AuthController) UserOnboard(user *models.User, req dto.UserOnboardRequestDTO) (*dto.UserOnboardResponseDTO, error) {
 	tx := ac.DB.Begin()
 	if tx.Error != nil {
@@ -55,6 +57,16 @@ func (ac *AuthController) UserOnboard(user *models.User, req dto.UserOnboardRequ
 		return nil, errors.New("Failed to create organization")
 	}
 
+	membership := models.OrganizationMember{
+		OrganizationID: organization.ID,
+		UserID:         user.ID,
+		Role:           models.OrganizationRoleOwner,
+	}
+	if err := tx.Create(&membership).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("Failed to create organization")
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return nil, errors.New("Failed to save data")
 	}
@@ -64,3 +76,24 @@ func (ac *AuthController) UserOnboard(user *models.User, req dto.UserOnboardRequ
 		Organization:   organization.Name,
 	}, nil
 }
+
+// ExplainUserPermissions lists the effective permissions of a user of the actor's
+// active organization with the role, department or direct grant each one comes from
+func (ac *AuthController) ExplainUserPermissions(actor *models.User, userID uint) (*dto.UserPermissionsExplainResponseDTO, error) {
+	var user models.User
+	result := ac.DB.Where("id = ?", userID).First(&user)
+	if result.RowsAffected == 0 {
+		return nil, errors.New("user not found")
+	}
+
+	if err := ac.checkSameOrganization(actor, &user); err != nil {
+		return nil, err
+	}
+
+	permissions, err := user.EffectivePermissions(ac.DB)
+	if err != nil {
+		return nil, errors.New("error resolving permissions")
+	}
+
+	return mapper.EffectivePermissionsToDTO(user, permissions), nil
+}
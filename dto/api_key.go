@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+type APIKeyCreateRequestDTO struct {
+	Name      string     `json:"name" binding:"required,max=150"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APIKeyResponseDTO struct {
+	ID               uint       `json:"id"`
+	OrganizationID   uint       `json:"organization_id"`
+	ServiceAccountID uint       `json:"service_account_id"`
+	Name             string     `json:"name"`
+	Prefix           string     `json:"prefix"`
+	Scopes           []string   `json:"scopes"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+	LastUsedAt       *time.Time `json:"last_used_at"`
+	LastUsedIP       string     `json:"last_used_ip"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// APIKeyCreateResponseDTO carries the full key, which is only returned once
+type APIKeyCreateResponseDTO struct {
+	APIKeyResponseDTO
+	Key string `json:"key"`
+}
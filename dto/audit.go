@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditLogQueryDTO struct {
+	Page       int
+	PageSize   int
+	UserID     *uint
+	Action     string
+	EntityType string
+	EntityID   string
+	Outcome    string
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+}
+
+type AuditLogResponseDTO struct {
+	ID             uint            `json:"id"`
+	UserID         *uint           `json:"user_id"`
+	UserEmail      string          `json:"user_email"`
+	OrganizationID *uint           `json:"organization_id,omitempty"`
+	Action         string          `json:"action"`
+	Details        json.RawMessage `json:"details"`
+	Method         string          `json:"method,omitempty"`
+	Route          string          `json:"route,omitempty"`
+	EntityType     string          `json:"entity_type,omitempty"`
+	EntityID       string          `json:"entity_id,omitempty"`
+	StatusCode     int             `json:"status_code,omitempty"`
+	Outcome        string          `json:"outcome,omitempty"`
+	IPAddress      string          `json:"ip_address"`
+	UserAgent      string          `json:"user_agent"`
+	RequestID      string          `json:"request_id,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
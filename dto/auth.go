@@ -24,5 +24,81 @@ type SignUpResponseDTO struct {
 }
 
 type SignInResponseDTO struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	MFARequired  bool   `json:"mfa_required,omitempty"`
+	MFAToken     string `json:"mfa_token,omitempty"`
+}
+
+type RefreshTokenRequestDTO struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequestDTO struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type ForceLogoutRequestDTO struct {
+	Reason string `json:"reason" binding:"omitempty,max=300"`
+}
+
+type VerifyEmailRequestDTO struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationRequestDTO struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ForgotPasswordRequestDTO struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequestDTO struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+type ChangePasswordRequestDTO struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+type TwoFactorSetupResponseDTO struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type TwoFactorCodeRequestDTO struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorBackupCodesResponseDTO struct {
+	BackupCodes []string `json:"backup_codes"`
+}
+
+type TwoFactorDisableRequestDTO struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type TwoFactorVerifyRequestDTO struct {
+	MFAToken   string `json:"mfa_token" binding:"required"`
+	Code       string `json:"code" binding:"required_without=BackupCode"`
+	BackupCode string `json:"backup_code" binding:"required_without=Code"`
+}
+
+// OIDCLoginResponseDTO starts a single sign on. The client keeps State and sends
+// the browser to AuthorizationURL.
+type OIDCLoginResponseDTO struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+}
+
+// OIDCCallbackRequestDTO carries the parameters the identity provider redirected back with
+type OIDCCallbackRequestDTO struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
 }
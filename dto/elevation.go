@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+type ElevationCreateRequestDTO struct {
+	PermissionName string `json:"permission_name" binding:"required"`
+	DurationHours  int    `json:"duration_hours" binding:"required,min=1"`
+	Justification  string `json:"justification" binding:"required,min=10,max=2000"`
+}
+
+type ElevationReviewRequestDTO struct {
+	Note string `json:"note" binding:"omitempty,max=500"`
+}
+
+type ElevationResponseDTO struct {
+	ID             uint       `json:"id"`
+	OrganizationID uint       `json:"organization_id"`
+	UserID         uint       `json:"user_id"`
+	UserEmail      string     `json:"user_email,omitempty"`
+	PermissionID   uint       `json:"permission_id"`
+	PermissionName string     `json:"permission_name"`
+	DurationHours  int        `json:"duration_hours"`
+	Justification  string     `json:"justification"`
+	Status         string     `json:"status"`
+	ReviewedBy     *uint      `json:"reviewed_by"`
+	ReviewedAt     *time.Time `json:"reviewed_at"`
+	ReviewNote     string     `json:"review_note"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+// JWKResponseDTO is a public key in RFC 7517 JSON Web Key format
+type JWKResponseDTO struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"` // OKP keys
+	X         string `json:"x,omitempty"`   // OKP keys
+	N         string `json:"n,omitempty"`   // RSA keys
+	E         string `json:"e,omitempty"`   // RSA keys
+}
+
+type JWKSResponseDTO struct {
+	Keys []JWKResponseDTO `json:"keys"`
+}
@@ -1,6 +1,156 @@
 package dto
 
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/text/currency"
+)
+
 type OrganizationDTO struct {
 	ID   uint   `json:"id"`
 	Name string `json:"name"`
+	Role string `json:"role,omitempty"`
+}
+
+type PasswordPolicyRequestDTO struct {
+	PasswordMaxAgeDays *int `json:"password_max_age_days" binding:"required,min=0,max=365"`
+}
+
+type PasswordPolicyResponseDTO struct {
+	OrganizationID     uint `json:"organization_id"`
+	PasswordMaxAgeDays int  `json:"password_max_age_days"`
+}
+
+type OrganizationInvitationRequestDTO struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=owner admin member"`
+}
+
+type OrganizationInvitationResponseDTO struct {
+	ID             uint       `json:"id"`
+	OrganizationID uint       `json:"organization_id"`
+	Email          string     `json:"email"`
+	Role           string     `json:"role"`
+	Status         string     `json:"status"`
+	InvitedBy      uint       `json:"invited_by"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type OrganizationInvitationAcceptRequestDTO struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type OrganizationMemberRoleRequestDTO struct {
+	Role string `json:"role" binding:"required,oneof=owner admin member"`
+}
+
+type OrganizationMemberResponseDTO struct {
+	UserID    uint      `json:"user_id"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Role      string    `json:"role"`
+	RoleID    *uint     `json:"role_id"` // Permission role within the organization
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+type OrganizationSettingsRequestDTO struct {
+	BaseCurrency             *string  `json:"base_currency"`
+	Timezone                 *string  `json:"timezone"`
+	Country                  *string  `json:"country" binding:"omitempty,min=1,max=100"`
+	DefaultTaxRate           *float64 `json:"default_tax_rate" binding:"omitempty,min=0,max=1"`
+	DefaultLowStockThreshold *int     `json:"default_low_stock_threshold" binding:"omitempty,min=0"`
+	SKUPrefix                *string  `json:"sku_prefix" binding:"omitempty,min=1,max=10"`
+	OrderNumberPrefix        *string  `json:"order_number_prefix" binding:"omitempty,min=1,max=10"`
+	UnitSystem               *string  `json:"unit_system" binding:"omitempty,oneof=metric imperial"`
+}
+
+type OrganizationSettingsResponseDTO struct {
+	OrganizationID           uint      `json:"organization_id"`
+	BaseCurrency             string    `json:"base_currency"`
+	Timezone                 string    `json:"timezone"`
+	Country                  string    `json:"country"`
+	DefaultTaxRate           float64   `json:"default_tax_rate"`
+	DefaultLowStockThreshold int       `json:"default_low_stock_threshold"`
+	SKUPrefix                string    `json:"sku_prefix"`
+	OrderNumberPrefix        string    `json:"order_number_prefix"`
+	UnitSystem               string    `json:"unit_system"`
+	UpdatedAt                time.Time `json:"updated_at"`
+}
+
+func (dto *OrganizationSettingsRequestDTO) Normalize() {
+	normalize := func(value *string, upper bool) {
+		if value == nil {
+			return
+		}
+		*value = strings.TrimSpace(*value)
+		if upper {
+			*value = strings.ToUpper(*value)
+		}
+	}
+
+	normalize(dto.BaseCurrency, true)
+	normalize(dto.Timezone, false)
+	normalize(dto.Country, false)
+	normalize(dto.SKUPrefix, true)
+	normalize(dto.OrderNumberPrefix, true)
+}
+
+func (dto *OrganizationSettingsRequestDTO) Validate() error {
+	if dto.BaseCurrency != nil {
+		unit, err := currency.ParseISO(*dto.BaseCurrency)
+		// XXX is the ISO code for "no currency"
+		if err != nil || unit == currency.XXX {
+			return errors.New("base_currency must be an ISO 4217 currency code")
+		}
+	}
+
+	if dto.Timezone != nil {
+		if *dto.Timezone == "" || *dto.Timezone == "Local" {
+			return errors.New("timezone must be an IANA time zone name")
+		}
+		if _, err := time.LoadLocation(*dto.Timezone); err != nil {
+			return errors.New("timezone must be an IANA time zone name")
+		}
+	}
+
+	for name, prefix := range map[string]*string{"sku_prefix": dto.SKUPrefix, "order_number_prefix": dto.OrderNumberPrefix} {
+		if prefix == nil {
+			continue
+		}
+		for _, char := range *prefix {
+			if !((char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9')) {
+				return errors.New(name + " must contain only letters and numbers")
+			}
+		}
+	}
+
+	return nil
+}
+
+type OrganizationExportResponseDTO struct {
+	ID             uint       `json:"id"`
+	OrganizationID uint       `json:"organization_id"`
+	RequestedBy    uint       `json:"requested_by"`
+	Status         string     `json:"status"`
+	FileSize       int64      `json:"file_size"`
+	Checksum       string     `json:"checksum"`
+	Error          string     `json:"error,omitempty"`
+	StartedAt      *time.Time `json:"started_at"`
+	CompletedAt    *time.Time `json:"completed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type OrganizationPurgeRequestDTO struct {
+	Confirm    string `json:"confirm" binding:"required"` // Must repeat the organization name
+	SkipExport bool   `json:"skip_export"`                // Purge without a completed export
+}
+
+type OrganizationPurgeResponseDTO struct {
+	OrganizationID uint             `json:"organization_id"`
+	Deleted        map[string]int64 `json:"deleted"`
 }
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"strings"
+	"time"
+)
+
+type PermissionTemplateRequestDTO struct {
+	Name          string `json:"name" binding:"required,min=2,max=100"`
+	Description   string `json:"description" binding:"omitempty,max=500"`
+	Category      string `json:"category" binding:"omitempty,max=50"`
+	PermissionIDs []uint `json:"permission_ids" binding:"required,min=1"`
+	IsActive      *bool  `json:"is_active"`
+}
+
+type PermissionTemplateResponseDTO struct {
+	ID            uint                    `json:"id"`
+	Name          string                  `json:"name"`
+	Description   string                  `json:"description"`
+	Category      string                  `json:"category"`
+	IsActive      bool                    `json:"is_active"`
+	CreatedAt     time.Time               `json:"created_at"`
+	UpdatedAt     time.Time               `json:"updated_at"`
+	Permissions   []PermissionResponseDTO `json:"permissions"`
+	LinkedRoleIDs []uint                  `json:"linked_role_ids"`
+}
+
+// PermissionTemplateApplyRequestDTO targets either a role or a user. Link only
+// applies to roles and keeps the role in sync with later template edits.
+type PermissionTemplateApplyRequestDTO struct {
+	TargetType string     `json:"target_type" binding:"required,oneof=role user"`
+	TargetID   uint       `json:"target_id" binding:"required,min=1"`
+	Link       bool       `json:"link"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	Reason     string     `json:"reason" binding:"omitempty,max=300"`
+}
+
+type PermissionTemplateDiffResponseDTO struct {
+	TargetType     string                  `json:"target_type"`
+	TargetID       uint                    `json:"target_id"`
+	ToGrant        []PermissionResponseDTO `json:"to_grant"`
+	AlreadyGranted []PermissionResponseDTO `json:"already_granted"`
+	Denied         []PermissionResponseDTO `json:"denied"` // Explicitly denied to the user, left denied
+	Linked         bool                    `json:"linked"`
+	Applied        bool                    `json:"applied"`
+}
+
+func (dto *PermissionTemplateRequestDTO) Normalize() {
+	dto.Name = strings.TrimSpace(dto.Name)
+	dto.Description = strings.TrimSpace(dto.Description)
+	dto.Category = strings.ToLower(strings.TrimSpace(dto.Category))
+}
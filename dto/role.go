@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"strings"
+	"time"
+)
+
+type RoleRequestDTO struct {
+	Name        string `json:"name" binding:"required,min=2,max=100"`
+	DisplayName string `json:"display_name" binding:"required,min=2,max=150"`
+	Description string `json:"description" binding:"omitempty,max=500"`
+	Level       *int   `json:"level" binding:"required,min=0,max=5"`
+	Color       string `json:"color" binding:"omitempty,max=20"`
+	IsDefault   *bool  `json:"is_default"`
+	IsActive    *bool  `json:"is_active"`
+}
+
+type PermissionResponseDTO struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Description string `json:"description"`
+	Module      string `json:"module"`
+	Action      string `json:"action"`
+	Resource    string `json:"resource"`
+}
+
+type PermissionModuleResponseDTO struct {
+	Module      string                  `json:"module"`
+	DisplayName string                  `json:"display_name"`
+	Permissions []PermissionResponseDTO `json:"permissions"`
+}
+
+type RoleResponseDTO struct {
+	ID          uint                          `json:"id"`
+	Name        string                        `json:"name"`
+	DisplayName string                        `json:"display_name"`
+	Description string                        `json:"description"`
+	Level       int                           `json:"level"`
+	Color       string                        `json:"color"`
+	IsDefault   bool                          `json:"is_default"`
+	IsSystem    bool                          `json:"is_system"`
+	IsActive    bool                          `json:"is_active"`
+	UserCount   int                           `json:"user_count"`
+	CreatedAt   time.Time                     `json:"created_at"`
+	UpdatedAt   time.Time                     `json:"updated_at"`
+	Permissions []PermissionModuleResponseDTO `json:"permissions,omitempty"`
+}
+
+type RolePermissionGrantRequestDTO struct {
+	PermissionIDs []uint `json:"permission_ids" binding:"required,min=1"`
+}
+
+// UserRoleAssignRequestDTO assigns a role to a user, a null role_id removes it
+type UserRoleAssignRequestDTO struct {
+	RoleID *uint `json:"role_id"`
+}
+
+func (dto *RoleRequestDTO) Normalize() {
+	dto.Name = strings.ToLower(strings.TrimSpace(dto.Name))
+	dto.DisplayName = strings.TrimSpace(dto.DisplayName)
+	dto.Description = strings.TrimSpace(dto.Description)
+
+	if dto.Color == "" {
+		dto.Color = "blue"
+	}
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type UserMeResponseDTO struct {
 	EmployeeID    string            `json:"employee_id"`
 	FirstName     string            `json:"first_name"`
@@ -23,3 +25,35 @@ type UserOnboardResponseDTO struct {
 	OrganizationID uint   `json:"organization_id"`
 	Organization   string `json:"organization"`
 }
+
+type UserSessionResponseDTO struct {
+	ID         uint      `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	LastSeenIP string    `json:"last_seen_ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+type PermissionSourceDTO struct {
+	Source     string `json:"source"` // owner, role, department or direct
+	SourceID   uint   `json:"source_id"`
+	SourceName string `json:"source_name"`
+	IsGranted  bool   `json:"is_granted"`
+	Depth      int    `json:"depth,omitempty"`
+}
+
+type EffectivePermissionDTO struct {
+	PermissionID uint                  `json:"permission_id"`
+	Name         string                `json:"name"`
+	Allowed      bool                  `json:"allowed"`
+	Sources      []PermissionSourceDTO `json:"sources"`
+}
+
+type UserPermissionsExplainResponseDTO struct {
+	UserID      uint                     `json:"user_id"`
+	IsSuperuser bool                     `json:"is_superuser"` // Superusers pass every permission check
+	Permissions []EffectivePermissionDTO `json:"permissions"`
+}
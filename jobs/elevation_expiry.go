@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"github.com/farhapartex/ainventory/models"
+	"gorm.io/gorm"
+)
+
+// StartElevationExpiry periodically closes approved permission elevations whose time
+// is up. It blocks, so run it in its own goroutine.
+func StartElevationExpiry(db *gorm.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := models.ExpirePermissionElevations(db)
+		if err != nil {
+			log.Printf("Elevation expiry failed: %v", err)
+		} else if expired > 0 {
+			log.Printf("Elevation expiry closed %d elevations", expired)
+		}
+
+		<-ticker.C
+	}
+}
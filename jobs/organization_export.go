@@ -0,0 +1,302 @@
+package jobs
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/tenant"
+	"gorm.io/gorm"
+)
+
+// exportManifest describes the archive, it is written last as manifest.json
+type exportManifest struct {
+	Version          int          `json:"version"`
+	ExportID         uint         `json:"export_id"`
+	OrganizationID   uint         `json:"organization_id"`
+	OrganizationName string       `json:"organization_name"`
+	RequestedBy      uint         `json:"requested_by"`
+	GeneratedAt      time.Time    `json:"generated_at"`
+	Files            []exportFile `json:"files"`
+}
+
+type exportFile struct {
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	Rows   int    `json:"rows"`
+	SHA256 string `json:"sha256"`
+}
+
+// exportUser leaves credentials and other secrets of the user out of the archive
+type exportUser struct {
+	ID          uint       `json:"id"`
+	EmployeeID  string     `json:"employee_id"`
+	Email       string     `json:"email"`
+	FirstName   string     `json:"first_name"`
+	LastName    string     `json:"last_name"`
+	Phone       string     `json:"phone"`
+	JobTitle    string     `json:"job_title"`
+	Status      string     `json:"status"`
+	Role        string     `json:"role"`
+	RoleID      *uint      `json:"role_id"`
+	JoinedAt    time.Time  `json:"joined_at"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// RunOrganizationExport builds the archive of an export request and records the
+// outcome on it. The archive holds the organization and its users as JSON and one
+// CSV file per business table, soft deleted rows included, next to a manifest.json
+// listing every file with its row count and checksum.
+func RunOrganizationExport(db *gorm.DB, exportID uint, dir string) {
+	db = db.WithContext(tenant.WithoutScope(context.Background()))
+
+	var export models.OrganizationExport
+	if err := db.Where("id = ?", exportID).First(&export).Error; err != nil {
+		log.Printf("Organization export %d not found: %v", exportID, err)
+		return
+	}
+
+	startedAt := time.Now()
+	db.Model(&export).Updates(map[string]interface{}{"status": models.ExportStatusRunning, "started_at": startedAt})
+
+	path, size, checksum, err := writeOrganizationArchive(db, &export, dir)
+	if err != nil {
+		log.Printf("Organization export %d failed: %v", export.ID, err)
+		db.Model(&export).Updates(map[string]interface{}{
+			"status":       models.ExportStatusFailed,
+			"error":        err.Error(),
+			"completed_at": time.Now(),
+		})
+		return
+	}
+
+	db.Model(&export).Updates(map[string]interface{}{
+		"status":       models.ExportStatusCompleted,
+		"file_path":    path,
+		"file_size":    size,
+		"checksum":     checksum,
+		"completed_at": time.Now(),
+	})
+	log.Printf("Organization export %d of organization %d completed in %s", export.ID, export.OrganizationID, time.Since(startedAt).Round(time.Millisecond))
+}
+
+// writeOrganizationArchive writes the archive to a temporary file and moves it in
+// place once complete, so a failed export never leaves a partial archive behind
+func writeOrganizationArchive(db *gorm.DB, export *models.OrganizationExport, dir string) (string, int64, string, error) {
+	var organization models.Organization
+	if err := db.Where("id = ?", export.OrganizationID).First(&organization).Error; err != nil {
+		return "", 0, "", fmt.Errorf("organization not found: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", 0, "", err
+	}
+	file, err := os.CreateTemp(dir, "export-*.tmp")
+	if err != nil {
+		return "", 0, "", err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	checksum := sha256.New()
+	archive := &exportArchive{zip: zip.NewWriter(io.MultiWriter(file, checksum))}
+	manifest := exportManifest{
+		Version:          1,
+		ExportID:         export.ID,
+		OrganizationID:   organization.ID,
+		OrganizationName: organization.Name,
+		RequestedBy:      export.RequestedBy,
+		GeneratedAt:      time.Now().UTC(),
+	}
+
+	if err := exportOrganizationData(db, archive, &organization); err != nil {
+		return "", 0, "", err
+	}
+
+	manifest.Files = archive.files
+	if err := archive.writeJSON("manifest.json", manifest, 1); err != nil {
+		return "", 0, "", err
+	}
+	if err := archive.zip.Close(); err != nil {
+		return "", 0, "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", 0, "", err
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("organization-%d-export-%d.zip", organization.ID, export.ID))
+	if err := os.Rename(file.Name(), path); err != nil {
+		return "", 0, "", err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	return path, info.Size(), hex.EncodeToString(checksum.Sum(nil)), nil
+}
+
+func exportOrganizationData(db *gorm.DB, archive *exportArchive, organization *models.Organization) error {
+	settings := models.SettingsFor(db.WithContext(tenant.WithOrganization(context.Background(), organization.ID)))
+	err := archive.writeJSON("organization.json", map[string]interface{}{
+		"id":         organization.ID,
+		"name":       organization.Name,
+		"address":    organization.Address,
+		"city":       organization.City,
+		"state":      organization.State,
+		"zip_code":   organization.ZipCode,
+		"country":    organization.Country,
+		"owner_id":   organization.OwnerID,
+		"created_at": organization.CreatedAt,
+		"settings":   settings,
+	}, 1)
+	if err != nil {
+		return err
+	}
+
+	var members []models.OrganizationMember
+	if err := db.Preload("User").Where("organization_id = ?", organization.ID).Order("id").Find(&members).Error; err != nil {
+		return err
+	}
+	users := make([]exportUser, 0, len(members))
+	for _, member := range members {
+		users = append(users, exportUser{
+			ID:          member.User.ID,
+			EmployeeID:  member.User.EmployeeID,
+			Email:       member.User.Email,
+			FirstName:   member.User.FirstName,
+			LastName:    member.User.LastName,
+			Phone:       member.User.Phone,
+			JobTitle:    member.User.JobTitle,
+			Status:      member.User.Status,
+			Role:        member.Role,
+			RoleID:      member.RoleID,
+			JoinedAt:    member.CreatedAt,
+			LastLoginAt: member.User.LastLoginAt,
+			CreatedAt:   member.User.CreatedAt,
+		})
+	}
+	if err := archive.writeJSON("users.json", users, len(users)); err != nil {
+		return err
+	}
+
+	for _, model := range models.TenantModels() {
+		statement := &gorm.Statement{DB: db}
+		if err := statement.Parse(model); err != nil {
+			return err
+		}
+		if err := archive.writeTable(db, statement.Schema.Table, organization.ID); err != nil {
+			return fmt.Errorf("exporting %s: %w", statement.Schema.Table, err)
+		}
+	}
+
+	return nil
+}
+
+// exportArchive writes files into the zip and remembers them for the manifest
+type exportArchive struct {
+	zip   *zip.Writer
+	files []exportFile
+}
+
+// writeFile adds one file, write returns the number of rows it wrote
+func (a *exportArchive) writeFile(name, format string, write func(io.Writer) (int, error)) error {
+	out, err := a.zip.Create(name)
+	if err != nil {
+		return err
+	}
+
+	checksum := sha256.New()
+	rows, err := write(io.MultiWriter(out, checksum))
+	if err != nil {
+		return err
+	}
+
+	a.files = append(a.files, exportFile{Name: name, Format: format, Rows: rows, SHA256: hex.EncodeToString(checksum.Sum(nil))})
+	return nil
+}
+
+func (a *exportArchive) writeJSON(name string, value interface{}, rows int) error {
+	return a.writeFile(name, "json", func(out io.Writer) (int, error) {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return rows, encoder.Encode(value)
+	})
+}
+
+// writeTable dumps every column of the organization's rows of a table as CSV
+func (a *exportArchive) writeTable(db *gorm.DB, table string, organizationID uint) error {
+	rows, err := db.Table(table).Where("organization_id = ?", organizationID).Order("id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return a.writeFile(table+".csv", "csv", func(out io.Writer) (int, error) {
+		return writeCSVRows(rows, out)
+	})
+}
+
+func writeCSVRows(rows *sql.Rows, out io.Writer) (int, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+
+	writer := csv.NewWriter(out)
+	if err := writer.Write(columns); err != nil {
+		return 0, err
+	}
+
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	record := make([]string, len(columns))
+
+	count := 0
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return count, err
+		}
+		for i, value := range values {
+			record[i] = csvValue(value)
+		}
+		if err := writer.Write(record); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+
+	writer.Flush()
+	return count, writer.Error()
+}
+
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,46 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"github.com/farhapartex/ainventory/models"
+	"gorm.io/gorm"
+)
+
+// rateLimitBucketIdleTime is how long an unused rate limit bucket is kept, policies
+// with a longer window are reset early
+const rateLimitBucketIdleTime = 24 * time.Hour
+
+// StartTokenCleanup periodically purges expired blacklisted and refresh tokens and
+// idle rate limit buckets, and expires stale organization invitations. It blocks,
+// so run it in its own goroutine.
+func StartTokenCleanup(db *gorm.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := models.PurgeExpiredTokens(db)
+		if err != nil {
+			log.Printf("Token cleanup failed: %v", err)
+		} else if purged > 0 {
+			log.Printf("Token cleanup removed %d expired rows", purged)
+		}
+
+		purged, err = models.PurgeIdleRateLimitBuckets(db, time.Now().Add(-rateLimitBucketIdleTime))
+		if err != nil {
+			log.Printf("Rate limit bucket cleanup failed: %v", err)
+		} else if purged > 0 {
+			log.Printf("Rate limit bucket cleanup removed %d idle buckets", purged)
+		}
+
+		expired, err := models.ExpireOrganizationInvitations(db)
+		if err != nil {
+			log.Printf("Invitation expiry failed: %v", err)
+		} else if expired > 0 {
+			log.Printf("Invitation expiry marked %d invitations as expired", expired)
+		}
+
+		<-ticker.C
+	}
+}
@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LogMailer is meant for development, it prints emails to the log or writes
+// them as .eml files into Dir when one is configured
+type LogMailer struct {
+	Dir  string
+	From string
+}
+
+func (m *LogMailer) Send(msg Message) error {
+	content := buildMessage(m.From, msg)
+
+	if m.Dir == "" {
+		log.Printf("Email to %s\n%s", msg.To, content)
+		return nil
+	}
+
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+
+	recipient := strings.NewReplacer("@", "_at_", "/", "_").Replace(msg.To)
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102T150405.000000000"), recipient)
+	return os.WriteFile(filepath.Join(m.Dir, name), content, 0o644)
+}
@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"log"
+	"os"
+	"strings"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional emails such as verification and password reset links
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// NewFromEnv builds the mailer selected by MAIL_DRIVER, "smtp" or "log" (default)
+func NewFromEnv() Mailer {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "no-reply@ainventory.local"
+	}
+
+	switch strings.ToLower(os.Getenv("MAIL_DRIVER")) {
+	case "smtp":
+		return &SMTPMailer{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		}
+	case "", "log":
+		return &LogMailer{
+			Dir:  os.Getenv("MAIL_LOG_DIR"),
+			From: from,
+		}
+	default:
+		log.Fatalf("Unknown MAIL_DRIVER %q", os.Getenv("MAIL_DRIVER"))
+		return nil
+	}
+}
@@ -1,10 +1,22 @@
 package main
 
 import (
+	"flag"
+	"log"
+	"os"
+	// Organization time zones must resolve on hosts without a zoneinfo database
+	_ "time/tzdata"
+
 	"github.com/farhapartex/ainventory/config"
 	"github.com/farhapartex/ainventory/controller"
+	"github.com/farhapartex/ainventory/jobs"
+	"github.com/farhapartex/ainventory/mailer"
 	"github.com/farhapartex/ainventory/middlewares"
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/oidc"
+	"github.com/farhapartex/ainventory/ratelimit"
 	"github.com/farhapartex/ainventory/routes"
+	"github.com/farhapartex/ainventory/seed"
 	"github.com/gin-gonic/gin"
 )
 
@@ -12,14 +24,36 @@ func main() {
 	config.ConnectDB()
 	config.MigrateDB()
 
-	authController := controller.NewAuthController(config.DB)
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		runSeed(os.Args[2:])
+		return
+	}
+
+	if err := config.LoadJWTKeys(); err != nil {
+		log.Fatalf("Error loading JWT keys: %v", err)
+	}
+
+	if failed, err := models.FailInterruptedExports(config.DB); err != nil {
+		log.Printf("Failed to close interrupted organization exports: %v", err)
+	} else if failed > 0 {
+		log.Printf("Marked %d interrupted organization exports as failed", failed)
+	}
+
+	go jobs.StartTokenCleanup(config.DB, config.TokenCleanupInterval())
+	go jobs.StartElevationExpiry(config.DB, config.ElevationExpiryInterval())
+
+	authController := controller.NewAuthController(config.DB, mailer.NewFromEnv(), oidc.NewFromEnv())
 
 	router := gin.Default()
+	if err := router.SetTrustedProxies(config.TrustedProxies()); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	router.Use(middlewares.RequestIDMiddleware())
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
 	router.Use(middlewares.CORSMiddleware())
 
-	routes.RegisterRoute(router, authController)
+	routes.RegisterRoute(router, authController, ratelimit.NewFromEnv(config.DB))
 
 	router.GET("/ping", func(ctx *gin.Context) {
 		ctx.JSON(200, gin.H{
@@ -29,3 +63,22 @@ func main() {
 
 	router.Run(":8000")
 }
+
+// runSeed handles "ainventory seed [-demo]", it writes the default data and exits
+func runSeed(args []string) {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	demo := flags.Bool("demo", false, "also seed demo products, customers and orders")
+	flags.Parse(args)
+
+	err := seed.Run(config.DB, seed.Options{
+		AdminEmail:    config.SeedAdminEmail(),
+		AdminPassword: config.SeedAdminPassword(),
+		Organization:  config.SeedOrganizationName(),
+		Demo:          *demo,
+	})
+	if err != nil {
+		log.Fatalf("Seeding failed: %v", err)
+	}
+
+	log.Println("Seeding completed!")
+}
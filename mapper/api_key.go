@@ -0,0 +1,22 @@
+package mapper
+
+import (
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/models"
+)
+
+func APIKeyModelToDTO(key models.APIKey) dto.APIKeyResponseDTO {
+	return dto.APIKeyResponseDTO{
+		ID:               key.ID,
+		OrganizationID:   key.OrganizationID,
+		ServiceAccountID: key.ServiceAccountID,
+		Name:             key.Name,
+		Prefix:           key.Prefix,
+		Scopes:           key.ScopeList(),
+		ExpiresAt:        key.ExpiresAt,
+		LastUsedAt:       key.LastUsedAt,
+		LastUsedIP:       key.LastUsedIP,
+		RevokedAt:        key.RevokedAt,
+		CreatedAt:        key.CreatedAt,
+	}
+}
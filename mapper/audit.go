@@ -0,0 +1,34 @@
+package mapper
+
+import (
+	"encoding/json"
+
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/models"
+)
+
+func UserHistoryModelToDTO(history models.UserHistory) dto.AuditLogResponseDTO {
+	details := json.RawMessage(history.Details)
+	if !json.Valid(details) {
+		details = json.RawMessage("{}")
+	}
+
+	return dto.AuditLogResponseDTO{
+		ID:             history.ID,
+		UserID:         history.UserID,
+		UserEmail:      history.User.Email,
+		OrganizationID: history.OrganizationID,
+		Action:         history.Action,
+		Details:        details,
+		Method:         history.Method,
+		Route:          history.Route,
+		EntityType:     history.EntityType,
+		EntityID:       history.EntityID,
+		StatusCode:     history.StatusCode,
+		Outcome:        history.Outcome,
+		IPAddress:      history.IPAddress,
+		UserAgent:      history.UserAgent,
+		RequestID:      history.RequestID,
+		CreatedAt:      history.CreatedAt,
+	}
+}
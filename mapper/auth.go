@@ -14,6 +14,6 @@ func SignUpDTOToModel(dto dto.SignUpRequestDTO) models.User {
 		Gender:        dto.Gender,
 		Status:        "active",
 		IsSuperuser:   false,
-		EmailVerified: true,
+		EmailVerified: false,
 	}
 }
@@ -0,0 +1,25 @@
+package mapper
+
+import (
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/models"
+)
+
+func ElevationModelToDTO(data models.PermissionElevationRequest) dto.ElevationResponseDTO {
+	return dto.ElevationResponseDTO{
+		ID:             data.ID,
+		OrganizationID: data.OrganizationID,
+		UserID:         data.UserID,
+		UserEmail:      data.User.Email,
+		PermissionID:   data.PermissionID,
+		PermissionName: data.Permission.Name,
+		DurationHours:  data.DurationHours,
+		Justification:  data.Justification,
+		Status:         data.Status,
+		ReviewedBy:     data.ReviewedBy,
+		ReviewedAt:     data.ReviewedAt,
+		ReviewNote:     data.ReviewNote,
+		ExpiresAt:      data.ExpiresAt,
+		CreatedAt:      data.CreatedAt,
+	}
+}
@@ -0,0 +1,39 @@
+package mapper
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+
+	"github.com/farhapartex/ainventory/config"
+	"github.com/farhapartex/ainventory/dto"
+)
+
+func JWTKeySetToJWKS(keySet *config.JWTKeySet) dto.JWKSResponseDTO {
+	keys := []dto.JWKResponseDTO{}
+	for _, key := range keySet.Keys {
+		jwk := dto.JWKResponseDTO{
+			KeyID:     key.ID,
+			Use:       "sig",
+			Algorithm: key.Method.Alg(),
+		}
+
+		switch public := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+
+		keys = append(keys, jwk)
+	}
+
+	return dto.JWKSResponseDTO{Keys: keys}
+}
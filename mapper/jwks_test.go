@@ -0,0 +1,55 @@
+package mapper
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"testing"
+
+	"github.com/farhapartex/ainventory/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TestJWTKeySetToJWKSPublishesRetiredKeys checks a rotated key set publishes the
+// retired key next to the signing key
+func TestJWTKeySetToJWKSPublishesRetiredKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signing := &config.JWTKey{ID: "2026-02", Method: jwt.SigningMethodEdDSA, Private: edPrivate, Public: edPublic}
+	keySet := &config.JWTKeySet{
+		Signing: signing,
+		Keys: []*config.JWTKey{
+			{ID: "2026-01", Method: jwt.SigningMethodRS256, Public: &rsaKey.PublicKey},
+			signing,
+		},
+	}
+
+	jwks := JWTKeySetToJWKS(keySet)
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("published %d keys, want 2", len(jwks.Keys))
+	}
+
+	retired := jwks.Keys[0]
+	if retired.KeyID != "2026-01" || retired.KeyType != "RSA" || retired.Algorithm != "RS256" || retired.Use != "sig" {
+		t.Errorf("retired key = %+v", retired)
+	}
+	if retired.N != base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()) || retired.E != "AQAB" {
+		t.Errorf("retired key modulus or exponent do not match the public key")
+	}
+
+	current := jwks.Keys[1]
+	if current.KeyID != "2026-02" || current.KeyType != "OKP" || current.Curve != "Ed25519" || current.Algorithm != "EdDSA" {
+		t.Errorf("signing key = %+v", current)
+	}
+	if current.X != base64.RawURLEncoding.EncodeToString(edPublic) {
+		t.Errorf("signing key x does not match the public key")
+	}
+}
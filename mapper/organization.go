@@ -0,0 +1,62 @@
+package mapper
+
+import (
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/models"
+)
+
+func OrganizationMemberModelToDTO(member models.OrganizationMember) dto.OrganizationMemberResponseDTO {
+	return dto.OrganizationMemberResponseDTO{
+		UserID:    member.UserID,
+		Email:     member.User.Email,
+		FirstName: member.User.FirstName,
+		LastName:  member.User.LastName,
+		Role:      member.Role,
+		RoleID:    member.RoleID,
+		JoinedAt:  member.CreatedAt,
+	}
+}
+
+func OrganizationInvitationModelToDTO(invitation models.OrganizationInvitation) dto.OrganizationInvitationResponseDTO {
+	return dto.OrganizationInvitationResponseDTO{
+		ID:             invitation.ID,
+		OrganizationID: invitation.OrganizationID,
+		Email:          invitation.Email,
+		Role:           invitation.Role,
+		Status:         invitation.EffectiveStatus(),
+		InvitedBy:      invitation.InvitedBy,
+		ExpiresAt:      invitation.ExpiresAt,
+		AcceptedAt:     invitation.AcceptedAt,
+		CreatedAt:      invitation.CreatedAt,
+	}
+}
+
+func OrganizationSettingsModelToDTO(settings models.OrganizationSettings) dto.OrganizationSettingsResponseDTO {
+	return dto.OrganizationSettingsResponseDTO{
+		OrganizationID:           settings.OrganizationID,
+		BaseCurrency:             settings.BaseCurrency,
+		Timezone:                 settings.Timezone,
+		Country:                  settings.Country,
+		DefaultTaxRate:           settings.DefaultTaxRate,
+		DefaultLowStockThreshold: settings.DefaultLowStockThreshold,
+		SKUPrefix:                settings.SKUPrefix,
+		OrderNumberPrefix:        settings.OrderNumberPrefix,
+		UnitSystem:               settings.UnitSystem,
+		UpdatedAt:                settings.UpdatedAt,
+	}
+}
+
+func OrganizationExportModelToDTO(export models.OrganizationExport) dto.OrganizationExportResponseDTO {
+	return dto.OrganizationExportResponseDTO{
+		ID:             export.ID,
+		OrganizationID: export.OrganizationID,
+		RequestedBy:    export.RequestedBy,
+		Status:         export.Status,
+		FileSize:       export.FileSize,
+		Checksum:       export.Checksum,
+		Error:          export.Error,
+		StartedAt:      export.StartedAt,
+		CompletedAt:    export.CompletedAt,
+		CreatedAt:      export.CreatedAt,
+	}
+}
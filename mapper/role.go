@@ -0,0 +1,113 @@
+package mapper
+
+import (
+	"sort"
+
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/models"
+)
+
+func RoleDTOToModel(data dto.RoleRequestDTO) *models.Role {
+	model := models.Role{
+		Name:        data.Name,
+		DisplayName: data.DisplayName,
+		Description: data.Description,
+		Level:       *data.Level,
+		Color:       data.Color,
+		IsActive:    true,
+	}
+
+	if data.IsDefault != nil {
+		model.IsDefault = *data.IsDefault
+	}
+	if data.IsActive != nil {
+		model.IsActive = *data.IsActive
+	}
+
+	return &model
+}
+
+func RoleModelToDTO(data models.Role) *dto.RoleResponseDTO {
+	return &dto.RoleResponseDTO{
+		ID:          data.ID,
+		Name:        data.Name,
+		DisplayName: data.DisplayName,
+		Description: data.Description,
+		Level:       data.Level,
+		Color:       data.Color,
+		IsDefault:   data.IsDefault,
+		IsSystem:    data.IsSystem,
+		IsActive:    data.IsActive,
+		UserCount:   data.UserCount,
+		CreatedAt:   data.CreatedAt,
+		UpdatedAt:   data.UpdatedAt,
+	}
+}
+
+func PermissionModelToDTO(data models.Permission) dto.PermissionResponseDTO {
+	return dto.PermissionResponseDTO{
+		ID:          data.ID,
+		Name:        data.Name,
+		DisplayName: data.DisplayName,
+		Description: data.Description,
+		Module:      data.Module,
+		Action:      data.Action,
+		Resource:    data.Resource,
+	}
+}
+
+// PermissionsByModuleToDTO turns permissions grouped by module into a list sorted by
+// module. displayNames holds PermissionModule display names, missing ones fall back
+// to the module name.
+func PermissionsByModuleToDTO(grouped map[string][]models.Permission, displayNames map[string]string) []dto.PermissionModuleResponseDTO {
+	modules := make([]dto.PermissionModuleResponseDTO, 0, len(grouped))
+	for module, permissions := range grouped {
+		displayName := displayNames[module]
+		if displayName == "" {
+			displayName = module
+		}
+
+		permissionDTOs := make([]dto.PermissionResponseDTO, len(permissions))
+		for i, permission := range permissions {
+			permissionDTOs[i] = PermissionModelToDTO(permission)
+		}
+		sort.Slice(permissionDTOs, func(i, j int) bool {
+			return permissionDTOs[i].Name < permissionDTOs[j].Name
+		})
+
+		modules = append(modules, dto.PermissionModuleResponseDTO{
+			Module:      module,
+			DisplayName: displayName,
+			Permissions: permissionDTOs,
+		})
+	}
+
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Module < modules[j].Module
+	})
+
+	return modules
+}
+
+func PermissionTemplateModelToDTO(data models.PermissionTemplate, linkedRoleIDs []uint) *dto.PermissionTemplateResponseDTO {
+	permissions := make([]dto.PermissionResponseDTO, len(data.Permissions))
+	for i, permission := range data.Permissions {
+		permissions[i] = PermissionModelToDTO(permission)
+	}
+
+	if linkedRoleIDs == nil {
+		linkedRoleIDs = []uint{}
+	}
+
+	return &dto.PermissionTemplateResponseDTO{
+		ID:            data.ID,
+		Name:          data.Name,
+		Description:   data.Description,
+		Category:      data.Category,
+		IsActive:      data.IsActive,
+		CreatedAt:     data.CreatedAt,
+		UpdatedAt:     data.UpdatedAt,
+		Permissions:   permissions,
+		LinkedRoleIDs: linkedRoleIDs,
+	}
+}
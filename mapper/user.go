@@ -14,9 +14,10 @@ func OrganizationModelToDTO(org *models.Organization) *dto.OrganizationDTO {
 
 func UserModelToUserProfileDTO(user *models.User) *dto.UserMeResponseDTO {
 
-	organizations := make([]dto.OrganizationDTO, len(user.Organizations))
-	for i, org := range user.Organizations {
-		organizations[i] = *OrganizationModelToDTO(&org)
+	organizations := make([]dto.OrganizationDTO, len(user.Memberships))
+	for i, membership := range user.Memberships {
+		organizations[i] = *OrganizationModelToDTO(&membership.Organization)
+		organizations[i].Role = membership.Role
 	}
 
 	return &dto.UserMeResponseDTO{
@@ -27,3 +28,45 @@ func UserModelToUserProfileDTO(user *models.User) *dto.UserMeResponseDTO {
 		Organizations: organizations,
 	}
 }
+
+func UserSessionModelToDTO(session models.UserSession, currentFamilyID string) dto.UserSessionResponseDTO {
+	return dto.UserSessionResponseDTO{
+		ID:         session.ID,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
+		LastSeenIP: session.LastSeenIP,
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+		ExpiresAt:  session.ExpiresAt,
+		Current:    session.FamilyID == currentFamilyID,
+	}
+}
+
+func EffectivePermissionsToDTO(user models.User, permissions []models.EffectivePermission) *dto.UserPermissionsExplainResponseDTO {
+	permissionDTOs := make([]dto.EffectivePermissionDTO, len(permissions))
+	for i, permission := range permissions {
+		sources := make([]dto.PermissionSourceDTO, len(permission.Grants))
+		for j, grant := range permission.Grants {
+			sources[j] = dto.PermissionSourceDTO{
+				Source:     grant.Source,
+				SourceID:   grant.SourceID,
+				SourceName: grant.SourceName,
+				IsGranted:  grant.IsGranted,
+				Depth:      grant.Depth,
+			}
+		}
+
+		permissionDTOs[i] = dto.EffectivePermissionDTO{
+			PermissionID: permission.PermissionID,
+			Name:         permission.PermissionName,
+			Allowed:      permission.Allowed,
+			Sources:      sources,
+		}
+	}
+
+	return &dto.UserPermissionsExplainResponseDTO{
+		UserID:      user.ID,
+		IsSuperuser: user.IsSuperuser,
+		Permissions: permissionDTOs,
+	}
+}
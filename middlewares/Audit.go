@@ -0,0 +1,90 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/farhapartex/ainventory/audit"
+	"github.com/farhapartex/ainventory/config"
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditMiddleware writes a UserHistory row for every mutating request, whatever
+// its outcome. Handlers can refine the record through the audit package. On
+// protected routes it has to run after AuthMiddleware and TenantMiddleware. Requests
+// without a signed in user are kept with the account named by audit.SetSubject,
+// or without a user.
+func AuditMiddleware(routePrefix string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isSafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		entry := audit.Begin(c, routePrefix)
+		c.Next()
+
+		var userID *uint
+		if user, err := utils.GetAuthenticatedUser(c); err == nil {
+			userID = &user.ID
+		} else if entry.Subject != "" {
+			var subject models.User
+			if config.DB.Select("id").Where("email = ?", strings.ToLower(strings.TrimSpace(entry.Subject))).Limit(1).Find(&subject).RowsAffected > 0 {
+				userID = &subject.ID
+			}
+		}
+
+		if apiKey, ok := c.Get("apiKey"); ok {
+			if key, ok := apiKey.(*models.APIKey); ok && key != nil {
+				entry.Details["api_key_id"] = key.ID
+			}
+		}
+
+		var organizationID *uint
+		if value, ok := c.Get("organizationId"); ok {
+			if id, ok := value.(uint); ok {
+				organizationID = &id
+			}
+		}
+
+		outcome := "success"
+		if c.Writer.Status() >= http.StatusBadRequest {
+			outcome = "failure"
+		}
+
+		details, err := json.Marshal(entry.Details)
+		if err != nil {
+			details = []byte("{}")
+		}
+
+		history := models.UserHistory{
+			UserID:         userID,
+			OrganizationID: organizationID,
+			Action:         entry.Action,
+			Details:        string(details),
+			IPAddress:      c.ClientIP(),
+			UserAgent:      truncate(c.Request.UserAgent(), 500),
+			Method:         c.Request.Method,
+			Route:          c.FullPath(),
+			EntityType:     truncate(entry.EntityType, 50),
+			EntityID:       truncate(entry.EntityID, 64),
+			StatusCode:     c.Writer.Status(),
+			Outcome:        outcome,
+			RequestID:      c.GetString("requestId"),
+		}
+		if err := config.DB.Create(&history).Error; err != nil {
+			log.Printf("Failed to write audit record for %s %s: %v", c.Request.Method, c.FullPath(), err)
+		}
+	}
+}
+
+func truncate(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
@@ -1,7 +1,7 @@
 package middlewares
 
 import (
-	"fmt"
+	"crypto/subtle"
 	"net/http"
 	"strings"
 
@@ -11,9 +11,15 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// APIKeyHeader is the header machine integrations use instead of a bearer token
+const APIKeyHeader = "X-API-Key"
+
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		fmt.Println("Inside of AuthMiddleware")
+		if apiKey := c.GetHeader(APIKeyHeader); apiKey != "" {
+			authenticateAPIKey(c, apiKey)
+			return
+		}
 
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -37,6 +43,12 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if claims.TokenType != "access" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token type"})
+			c.Abort()
+			return
+		}
+
 		var user models.User
 		result := config.DB.Where("id = ?", claims.UserID).First(&user)
 		if result.Error != nil {
@@ -51,10 +63,72 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		fmt.Println("User authenticated successfully:", user.Email)
+		if claims.TokenVersion != user.TokenVersion {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been logged out"})
+			c.Abort()
+			return
+		}
+
+		if claims.ID == "" || user.IsTokenBlacklisted(config.DB, claims.ID) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+
+		if claims.FamilyID != "" {
+			session, err := models.TouchSession(config.DB, claims.FamilyID, c.ClientIP())
+			if err != nil || session.RevokedAt != nil || session.UserID != user.ID {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+				c.Abort()
+				return
+			}
+		}
+
 		c.Set("user", user)
 		c.Set("userId", user.ID)
+		c.Set("claims", claims)
+		c.Set("token", tokenString)
 
 		c.Next()
 	}
 }
+
+// authenticateAPIKey accepts a key created for an organization and continues as
+// its service account. The key itself is stored in the context as "apiKey".
+func authenticateAPIKey(c *gin.Context, rawKey string) {
+	prefix, ok := utils.ParseAPIKeyPrefix(rawKey)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+		c.Abort()
+		return
+	}
+
+	var apiKey models.APIKey
+	result := config.DB.Preload("ServiceAccount").Where("prefix = ?", prefix).First(&apiKey)
+	if result.Error != nil || subtle.ConstantTimeCompare([]byte(apiKey.KeyHash), []byte(utils.HashToken(rawKey))) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+		c.Abort()
+		return
+	}
+
+	if !apiKey.IsUsable() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "API key has expired or been revoked"})
+		c.Abort()
+		return
+	}
+
+	user := apiKey.ServiceAccount
+	if !user.IsServiceAccount || user.Status != "active" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Account is not active"})
+		c.Abort()
+		return
+	}
+
+	apiKey.TouchLastUsed(config.DB, c.ClientIP())
+
+	c.Set("user", user)
+	c.Set("userId", user.ID)
+	c.Set("apiKey", &apiKey)
+
+	c.Next()
+}
@@ -29,7 +29,8 @@ func CORSMiddleware() gin.HandlerFunc {
 
 		ctx.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		ctx.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		ctx.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		ctx.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Request-ID, X-Organization-ID")
+		ctx.Writer.Header().Set("Access-Control-Expose-Headers", "RateLimit-Policy, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After, X-Request-ID")
 		ctx.Writer.Header().Set("Access-Control-Max-Age", "86400")
 
 		if ctx.Request.Method == "OPTIONS" {
@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/farhapartex/ainventory/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// PasswordPolicyMiddleware restricts users who must change their password, because an
+// administrator asked for it or because it expired, to the given routes. It has to run
+// after AuthMiddleware.
+func PasswordPolicyMiddleware(allowedRoutes ...string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(allowedRoutes))
+	for _, route := range allowedRoutes {
+		allowed[route] = true
+	}
+
+	return func(c *gin.Context) {
+		user, err := utils.GetAuthenticatedUser(c)
+		if err != nil || !user.ShouldChangePassword() || allowed[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		code := "PASSWORD_CHANGE_REQUIRED"
+		message := "You must change your password before continuing"
+		if !user.MustChangePassword && user.IsPasswordExpired() {
+			code = "PASSWORD_EXPIRED"
+			message = "Your password has expired, please change it to continue"
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": message, "code": code})
+		c.Abort()
+	}
+}
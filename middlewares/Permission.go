@@ -0,0 +1,54 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/farhapartex/ainventory/config"
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission lets the request through only when the authenticated user holds
+// every given permission in the active organization, through their role, their
+// departments or a direct grant. Superusers always pass. Requests made with an API
+// key are limited to the scopes of the key. It has to run after TenantMiddleware.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := utils.GetAuthenticatedUser(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		apiKey, _ := c.Get("apiKey")
+		key, _ := apiKey.(*models.APIKey)
+
+		for _, permission := range permissions {
+			if key != nil {
+				if !key.HasScope(permission) {
+					denyPermission(c, permission)
+					return
+				}
+				continue
+			}
+
+			if !user.IsSuperuser && !user.HasPermission(config.DB.WithContext(c.Request.Context()), permission) {
+				denyPermission(c, permission)
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+func denyPermission(c *gin.Context, permission string) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":      "Permission denied",
+		"code":       "PERMISSION_DENIED",
+		"permission": permission,
+	})
+	c.Abort()
+}
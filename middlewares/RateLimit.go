@@ -0,0 +1,80 @@
+package middlewares
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitKeyFunc names the client a request is counted against
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// RateLimitByIP counts requests per client IP, for routes used before signing in
+func RateLimitByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimitByClient counts requests per API key or user, falling back to the IP
+// when the request is not authenticated. It has to run after AuthMiddleware.
+func RateLimitByClient(c *gin.Context) string {
+	if apiKey, ok := c.Get("apiKey"); ok {
+		if key, ok := apiKey.(*models.APIKey); ok && key != nil {
+			return fmt.Sprintf("key:%d", key.ID)
+		}
+	}
+	if userID, ok := c.Get("userId"); ok {
+		return fmt.Sprintf("user:%v", userID)
+	}
+	return RateLimitByIP(c)
+}
+
+// RateLimit rejects requests with 429 once the client has used up its bucket of
+// the policy. Responses carry the RateLimit-* headers and rejected ones a
+// Retry-After. When the limiter fails the request is let through.
+func RateLimit(limiter ratelimit.Limiter, policy ratelimit.Policy, key RateLimitKeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !policy.Enabled() || (policy.WritesOnly && isSafeMethod(c.Request.Method)) {
+			c.Next()
+			return
+		}
+
+		result, err := limiter.Take(c.Request.Context(), policy, policy.Name+":"+key(c))
+		if err != nil {
+			log.Printf("Rate limiter failed for policy %s: %v", policy.Name, err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", policy.String())
+		c.Header("RateLimit-Limit", strconv.Itoa(policy.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", ceilSeconds(result.Reset))
+
+		if !result.Allowed {
+			c.Header("Retry-After", ceilSeconds(result.RetryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error": "Too many requests, please try again later",
+				"code":  "RATE_LIMITED",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
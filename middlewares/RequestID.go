@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"regexp"
+
+	"github.com/farhapartex/ainventory/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestIDMiddleware tags every request with an ID, reusing the one sent by a
+// proxy when it looks sane, and echoes it in the response
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID, _ = utils.GenerateRandomString(16)
+		}
+
+		c.Set("requestId", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
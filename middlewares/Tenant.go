@@ -0,0 +1,137 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/farhapartex/ainventory/config"
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/tenant"
+	"github.com/farhapartex/ainventory/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizationHeader selects the active organization of a user who belongs to several
+const OrganizationHeader = "X-Organization-ID"
+
+// TenantMiddleware resolves the active organization of the request and puts it in
+// the request context, where the tenant scope of the database picks it up. API keys
+// always act for their own organization. Users choose one with X-Organization-ID,
+// otherwise the org_id claim of their token applies. It has to run after AuthMiddleware.
+func TenantMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := utils.GetAuthenticatedUser(c)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		var requested uint
+		if header := c.GetHeader(OrganizationHeader); header != "" {
+			id, err := strconv.ParseUint(header, 10, 32)
+			if err != nil || id == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID", "code": "INVALID_ORGANIZATION"})
+				c.Abort()
+				return
+			}
+			requested = uint(id)
+		}
+
+		var organizationID uint
+		if value, ok := c.Get("apiKey"); ok {
+			apiKey := value.(*models.APIKey)
+			if requested != 0 && requested != apiKey.OrganizationID {
+				denyOrganization(c)
+				return
+			}
+			organizationID = apiKey.OrganizationID
+		} else if requested != 0 {
+			if !canAccessOrganization(user, requested) {
+				denyOrganization(c)
+				return
+			}
+			organizationID = requested
+		} else if claims, _, err := utils.GetTokenClaims(c); err == nil && claims.OrganizationID != 0 {
+			// A user removed from the organization since sign in simply has no active one
+			if canAccessOrganization(user, claims.OrganizationID) {
+				organizationID = claims.OrganizationID
+			}
+		}
+
+		if organizationID != 0 {
+			c.Set("organizationId", organizationID)
+			c.Request = c.Request.WithContext(tenant.WithOrganization(c.Request.Context(), organizationID))
+		}
+
+		c.Next()
+	}
+}
+
+// PathOrganization makes the organization named by a path parameter the active one,
+// so the permissions of routes acting on that organization are checked within it.
+// Invalid ids are left to the handler. It has to run after TenantMiddleware.
+func PathOrganization(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := utils.GetAuthenticatedUser(c)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		id, err := strconv.ParseUint(c.Param(param), 10, 32)
+		if err != nil || id == 0 {
+			c.Next()
+			return
+		}
+		organizationID := uint(id)
+
+		if active, ok := tenant.OrganizationID(c.Request.Context()); ok && active == organizationID {
+			c.Next()
+			return
+		}
+		// API keys act for their own organization only
+		if _, ok := c.Get("apiKey"); ok || !canAccessOrganization(user, organizationID) {
+			denyOrganization(c)
+			return
+		}
+
+		c.Set("organizationId", organizationID)
+		c.Request = c.Request.WithContext(tenant.WithOrganization(c.Request.Context(), organizationID))
+
+		c.Next()
+	}
+}
+
+// RequireOrganization rejects requests without an active organization, it guards
+// routes that read or write business data
+func RequireOrganization() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := tenant.OrganizationID(c.Request.Context()); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Select an organization with the " + OrganizationHeader + " header",
+				"code":  "ORGANIZATION_REQUIRED",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// canAccessOrganization reports whether the user is a member of the organization.
+// Superusers can act in every existing organization.
+func canAccessOrganization(user *models.User, organizationID uint) bool {
+	var count int64
+	if user.IsSuperuser {
+		config.DB.Model(&models.Organization{}).Where("id = ?", organizationID).Count(&count)
+	} else {
+		config.DB.Model(&models.OrganizationMember{}).Where("organization_id = ? AND user_id = ?", organizationID, user.ID).Count(&count)
+	}
+	return count > 0
+}
+
+func denyOrganization(c *gin.Context) {
+	c.JSON(http.StatusForbidden, gin.H{"error": "You are not a member of this organization", "code": "ORGANIZATION_ACCESS_DENIED"})
+	c.Abort()
+}
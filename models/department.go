@@ -10,8 +10,9 @@ import (
 
 type Department struct {
 	ID              uint           `json:"id" gorm:"primaryKey;autoIncrement"`
-	Name            string         `json:"name" gorm:"uniqueIndex;not null;size:100" binding:"required"`
-	Code            string         `json:"code" gorm:"uniqueIndex;not null;size:20" binding:"required"`
+	OrganizationID  uint           `json:"organization_id" gorm:"not null;uniqueIndex:idx_departments_org_name,priority:1;uniqueIndex:idx_departments_org_code,priority:1"`
+	Name            string         `json:"name" gorm:"uniqueIndex:idx_departments_org_name,priority:2;not null;size:100" binding:"required"`
+	Code            string         `json:"code" gorm:"uniqueIndex:idx_departments_org_code,priority:2;not null;size:20" binding:"required"`
 	Description     string         `json:"description" gorm:"size:500"`
 	ParentID        *uint          `json:"parent_id" gorm:"index"`
 	ManagerID       *uint          `json:"manager_id" gorm:"index"`
@@ -38,15 +39,16 @@ type Department struct {
 }
 
 type DepartmentRole struct {
-	ID           uint      `json:"id" gorm:"primaryKey"`
-	DepartmentID uint      `json:"department_id" gorm:"not null;index"`
-	Name         string    `json:"name" gorm:"not null;size:100" binding:"required"`
-	Description  string    `json:"description" gorm:"size:300"`
-	Level        int       `json:"level" gorm:"default:1;check:level >= 1 AND level <= 10"`
-	IsManagerial bool      `json:"is_managerial" gorm:"default:false"`
-	IsActive     bool      `json:"is_active" gorm:"default:true"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"not null;index"`
+	DepartmentID   uint      `json:"department_id" gorm:"not null;index"`
+	Name           string    `json:"name" gorm:"not null;size:100" binding:"required"`
+	Description    string    `json:"description" gorm:"size:300"`
+	Level          int       `json:"level" gorm:"default:1;check:level >= 1 AND level <= 10"`
+	IsManagerial   bool      `json:"is_managerial" gorm:"default:false"`
+	IsActive       bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 
 	Department Department `json:"department,omitempty" gorm:"foreignKey:DepartmentID"`
 	Users      []User     `json:"users,omitempty" gorm:"foreignKey:DepartmentRoleID"`
@@ -56,10 +58,11 @@ type DepartmentRole struct {
 }
 
 type DepartmentHierarchy struct {
-	ID           uint `json:"id" gorm:"primaryKey"`
-	AncestorID   uint `json:"ancestor_id" gorm:"not null;index"`
-	DescendantID uint `json:"descendant_id" gorm:"not null;index"`
-	Depth        int  `json:"depth" gorm:"not null;default:0"`
+	ID             uint `json:"id" gorm:"primaryKey"`
+	OrganizationID uint `json:"organization_id" gorm:"not null;index"`
+	AncestorID     uint `json:"ancestor_id" gorm:"not null;index"`
+	DescendantID   uint `json:"descendant_id" gorm:"not null;index"`
+	Depth          int  `json:"depth" gorm:"not null;default:0"`
 
 	Ancestor   Department `json:"ancestor,omitempty" gorm:"foreignKey:AncestorID"`
 	Descendant Department `json:"descendant,omitempty" gorm:"foreignKey:DescendantID"`
@@ -70,6 +73,7 @@ type DepartmentHierarchy struct {
 
 type DepartmentBudget struct {
 	ID                uint       `json:"id" gorm:"primaryKey"`
+	OrganizationID    uint       `json:"organization_id" gorm:"not null;index"`
 	DepartmentID      uint       `json:"department_id" gorm:"not null;index"`
 	FiscalYear        int        `json:"fiscal_year" gorm:"not null;index"`
 	TotalBudget       float64    `json:"total_budget" gorm:"type:decimal(15,2);default:0"`
@@ -91,13 +95,14 @@ type DepartmentBudget struct {
 }
 
 type DepartmentPermission struct {
-	ID           uint      `json:"id" gorm:"primaryKey"`
-	DepartmentID uint      `json:"department_id" gorm:"not null;index"`
-	PermissionID uint      `json:"permission_id" gorm:"not null;index"`
-	IsGranted    bool      `json:"is_granted" gorm:"default:true"`
-	GrantedBy    uint      `json:"granted_by" gorm:"not null;index"`
-	GrantedAt    time.Time `json:"granted_at" gorm:"autoCreateTime"`
-	Reason       string    `json:"reason" gorm:"size:300"`
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"not null;index"`
+	DepartmentID   uint      `json:"department_id" gorm:"not null;index"`
+	PermissionID   uint      `json:"permission_id" gorm:"not null;index"`
+	IsGranted      bool      `json:"is_granted" gorm:"default:true"`
+	GrantedBy      uint      `json:"granted_by" gorm:"not null;index"`
+	GrantedAt      time.Time `json:"granted_at" gorm:"autoCreateTime"`
+	Reason         string    `json:"reason" gorm:"size:300"`
 
 	Department    Department `json:"department,omitempty" gorm:"foreignKey:DepartmentID"`
 	Permission    Permission `json:"permission,omitempty" gorm:"foreignKey:PermissionID"`
@@ -108,17 +113,18 @@ type DepartmentPermission struct {
 }
 
 type DepartmentHistory struct {
-	ID           uint      `json:"id" gorm:"primaryKey"`
-	DepartmentID uint      `json:"department_id" gorm:"not null;index"`
-	Action       string    `json:"action" gorm:"not null;size:50"`
-	FieldName    string    `json:"field_name" gorm:"size:50"`
-	OldValue     string    `json:"old_value" gorm:"type:text"`
-	NewValue     string    `json:"new_value" gorm:"type:text"`
-	ChangedBy    uint      `json:"changed_by" gorm:"not null;index"`
-	Reason       string    `json:"reason" gorm:"size:500"`
-	IPAddress    string    `json:"ip_address" gorm:"size:45"`
-	UserAgent    string    `json:"user_agent" gorm:"size:500"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"not null;index"`
+	DepartmentID   uint      `json:"department_id" gorm:"not null;index"`
+	Action         string    `json:"action" gorm:"not null;size:50"`
+	FieldName      string    `json:"field_name" gorm:"size:50"`
+	OldValue       string    `json:"old_value" gorm:"type:text"`
+	NewValue       string    `json:"new_value" gorm:"type:text"`
+	ChangedBy      uint      `json:"changed_by" gorm:"not null;index"`
+	Reason         string    `json:"reason" gorm:"size:500"`
+	IPAddress      string    `json:"ip_address" gorm:"size:45"`
+	UserAgent      string    `json:"user_agent" gorm:"size:500"`
+	CreatedAt      time.Time `json:"created_at"`
 
 	Department    Department `json:"department,omitempty" gorm:"foreignKey:DepartmentID"`
 	ChangedByUser User       `json:"changed_by_user,omitempty" gorm:"foreignKey:ChangedBy"`
@@ -144,6 +150,14 @@ func (d *Department) AfterUpdate(tx *gorm.DB) error {
 	return d.UpdateEmployeeCount(tx)
 }
 
+// BeforeCreate hook for DepartmentBudget
+func (b *DepartmentBudget) BeforeCreate(tx *gorm.DB) error {
+	if b.Currency == "" {
+		b.Currency = SettingsFor(tx).BaseCurrency
+	}
+	return nil
+}
+
 // BeforeDelete hook for Department
 func (d *Department) BeforeDelete(tx *gorm.DB) error {
 	var userCount int64
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/farhapartex/ainventory/tenant"
+	"gorm.io/gorm"
+)
+
+// Sources a user can receive a permission from
+const (
+	PermissionSourceRole       = "role"
+	PermissionSourceDepartment = "department"
+	PermissionSourceDirect     = "direct"
+	PermissionSourceOwner      = "owner"
+)
+
+// PermissionGrant is one grant or explicit deny of a permission to a user
+type PermissionGrant struct {
+	PermissionID   uint   `json:"permission_id"`
+	PermissionName string `json:"permission_name"`
+	Source         string `json:"source"`
+	SourceID       uint   `json:"source_id"`
+	SourceName     string `json:"source_name"`
+	IsGranted      bool   `json:"is_granted"`
+	Depth          int    `json:"depth"` // Distance to the user's own department, 0 for other sources
+}
+
+// EffectivePermission is the outcome of every grant and deny of one permission
+type EffectivePermission struct {
+	PermissionID   uint
+	PermissionName string
+	Allowed        bool
+	Grants         []PermissionGrant
+}
+
+// PermissionGrants collects the grants and denies of a user from their role, their
+// department and its ancestors, and direct UserPermission rows, all of them within
+// the organization active on tx. Owners of the organization are granted every
+// permission. Without an active organization there are none.
+// An empty permissionName returns the grants of every permission.
+func (u *User) PermissionGrants(tx *gorm.DB, permissionName string) ([]PermissionGrant, error) {
+	organizationID, ok := tenant.OrganizationID(tx.Statement.Context)
+	if !ok {
+		return nil, nil
+	}
+
+	var grants []PermissionGrant
+	membership := u.Membership(tx)
+
+	if membership != nil && membership.Role == OrganizationRoleOwner {
+		var ownerGrants []PermissionGrant
+		query := tx.Table("permissions").
+			Select("permissions.id AS permission_id, permissions.name AS permission_name, ? AS source, ? AS source_id, ? AS source_name, TRUE AS is_granted, 0 AS depth", PermissionSourceOwner, organizationID, OrganizationRoleOwner).
+			Where("permissions.is_active = ? AND permissions.deleted_at IS NULL", true)
+		if permissionName != "" {
+			query = query.Where("permissions.name = ?", permissionName)
+		}
+		if err := query.Scan(&ownerGrants).Error; err != nil {
+			return nil, err
+		}
+		grants = append(grants, ownerGrants...)
+	}
+
+	if membership != nil && membership.RoleID != nil {
+		var roleGrants []PermissionGrant
+		query := tx.Table("role_permissions").
+			Select("permissions.id AS permission_id, permissions.name AS permission_name, ? AS source, roles.id AS source_id, roles.name AS source_name, TRUE AS is_granted, 0 AS depth", PermissionSourceRole).
+			Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+			Joins("JOIN roles ON roles.id = role_permissions.role_id").
+			Where("role_permissions.role_id = ? AND permissions.is_active = ? AND roles.is_active = ? AND roles.deleted_at IS NULL", *membership.RoleID, true, true)
+		if permissionName != "" {
+			query = query.Where("permissions.name = ?", permissionName)
+		}
+		if err := query.Scan(&roleGrants).Error; err != nil {
+			return nil, err
+		}
+		grants = append(grants, roleGrants...)
+	}
+
+	if u.DepartmentID != nil {
+		var departmentGrants []PermissionGrant
+		query := tx.Table("department_permissions").
+			Select("permissions.id AS permission_id, permissions.name AS permission_name, ? AS source, departments.id AS source_id, departments.name AS source_name, department_permissions.is_granted, department_hierarchies.depth", PermissionSourceDepartment).
+			Joins("JOIN department_hierarchies ON department_hierarchies.ancestor_id = department_permissions.department_id").
+			Joins("JOIN departments ON departments.id = department_permissions.department_id").
+			Joins("JOIN permissions ON permissions.id = department_permissions.permission_id").
+			Where("department_hierarchies.descendant_id = ? AND departments.organization_id = ? AND permissions.is_active = ? AND departments.deleted_at IS NULL", *u.DepartmentID, organizationID, true)
+		if permissionName != "" {
+			query = query.Where("permissions.name = ?", permissionName)
+		}
+		if err := query.Scan(&departmentGrants).Error; err != nil {
+			return nil, err
+		}
+		grants = append(grants, departmentGrants...)
+	}
+
+	var directGrants []PermissionGrant
+	query := tx.Table("user_permissions").
+		Select("permissions.id AS permission_id, permissions.name AS permission_name, ? AS source, user_permissions.id AS source_id, user_permissions.reason AS source_name, user_permissions.is_granted, 0 AS depth", PermissionSourceDirect).
+		Joins("JOIN permissions ON permissions.id = user_permissions.permission_id").
+		Where("user_permissions.organization_id = ? AND user_permissions.user_id = ? AND permissions.is_active = ?", organizationID, u.ID, true).
+		Where("user_permissions.expires_at IS NULL OR user_permissions.expires_at > ?", time.Now())
+	if permissionName != "" {
+		query = query.Where("permissions.name = ?", permissionName)
+	}
+	if err := query.Scan(&directGrants).Error; err != nil {
+		return nil, err
+	}
+	grants = append(grants, directGrants...)
+
+	return grants, nil
+}
+
+// EffectivePermissions resolves the grants of a user per permission. A permission
+// is allowed when at least one source grants it and no source explicitly denies it.
+func (u *User) EffectivePermissions(tx *gorm.DB) ([]EffectivePermission, error) {
+	grants, err := u.PermissionGrants(tx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return resolvePermissionGrants(grants), nil
+}
+
+func resolvePermissionGrants(grants []PermissionGrant) []EffectivePermission {
+	byPermission := make(map[uint]*EffectivePermission)
+	denied := make(map[uint]bool)
+	for _, grant := range grants {
+		permission, ok := byPermission[grant.PermissionID]
+		if !ok {
+			permission = &EffectivePermission{
+				PermissionID:   grant.PermissionID,
+				PermissionName: grant.PermissionName,
+			}
+			byPermission[grant.PermissionID] = permission
+		}
+		permission.Grants = append(permission.Grants, grant)
+		if !grant.IsGranted {
+			denied[grant.PermissionID] = true
+		}
+	}
+
+	permissions := make([]EffectivePermission, 0, len(byPermission))
+	for id, permission := range byPermission {
+		permission.Allowed = !denied[id]
+		permissions = append(permissions, *permission)
+	}
+
+	sort.Slice(permissions, func(i, j int) bool {
+		return permissions[i].PermissionName < permissions[j].PermissionName
+	})
+
+	return permissions
+}
@@ -0,0 +1,75 @@
+package models_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/tenant"
+	"gorm.io/gorm/clause"
+)
+
+func TestPermissionGrantsFollowActiveOrganization(t *testing.T) {
+	db := testDB(t)
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	suffix := time.Now().UnixNano()
+	create := func(value interface{}) {
+		t.Helper()
+		if err := tx.Omit(clause.Associations).Create(value).Error; err != nil {
+			t.Fatalf("creating %T: %v", value, err)
+		}
+	}
+
+	user := &models.User{FirstName: "member", LastName: "Test", Email: fmt.Sprintf("member-%d@example.com", suffix), Password: "password123"}
+	create(user)
+
+	rolePermission := &models.Permission{Name: fmt.Sprintf("role_test_%d", suffix), DisplayName: "Role test", Module: "grant_test", Action: "view"}
+	create(rolePermission)
+	directPermission := &models.Permission{Name: fmt.Sprintf("direct_test_%d", suffix), DisplayName: "Direct test", Module: "grant_test", Action: "view"}
+	create(directPermission)
+
+	role := &models.Role{Name: fmt.Sprintf("grant_test_%d", suffix), DisplayName: "Grant test", Level: 1, CreatedBy: user.ID, IsActive: true}
+	create(role)
+	create(&models.RolePermission{RoleID: role.ID, PermissionID: rolePermission.ID, GrantedBy: user.ID})
+
+	withRole := &models.Organization{Name: fmt.Sprintf("With role %d", suffix), OwnerID: user.ID}
+	create(withRole)
+	create(&models.OrganizationMember{OrganizationID: withRole.ID, UserID: user.ID, Role: models.OrganizationRoleMember, RoleID: &role.ID})
+
+	withGrant := &models.Organization{Name: fmt.Sprintf("With grant %d", suffix), OwnerID: user.ID}
+	create(withGrant)
+	create(&models.OrganizationMember{OrganizationID: withGrant.ID, UserID: user.ID, Role: models.OrganizationRoleMember})
+	create(&models.UserPermission{OrganizationID: withGrant.ID, UserID: user.ID, PermissionID: directPermission.ID, IsGranted: true, GrantedBy: user.ID})
+
+	owned := &models.Organization{Name: fmt.Sprintf("Owned %d", suffix), OwnerID: user.ID}
+	create(owned)
+	create(&models.OrganizationMember{OrganizationID: owned.ID, UserID: user.ID, Role: models.OrganizationRoleOwner})
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		wantRole   bool
+		wantDirect bool
+	}{
+		{"organization of the role", tenant.WithOrganization(context.Background(), withRole.ID), true, false},
+		{"organization of the direct grant", tenant.WithOrganization(context.Background(), withGrant.ID), false, true},
+		{"organization the user owns", tenant.WithOrganization(context.Background(), owned.ID), true, true},
+		{"no active organization", context.Background(), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scoped := tx.WithContext(tt.ctx)
+			if got := user.HasPermission(scoped, rolePermission.Name); got != tt.wantRole {
+				t.Errorf("role permission = %v, want %v", got, tt.wantRole)
+			}
+			if got := user.HasPermission(scoped, directPermission.Name); got != tt.wantDirect {
+				t.Errorf("direct permission = %v, want %v", got, tt.wantDirect)
+			}
+		})
+	}
+}
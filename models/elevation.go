@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Statuses of a PermissionElevationRequest
+const (
+	ElevationPending   = "pending"
+	ElevationApproved  = "approved"
+	ElevationDenied    = "denied"
+	ElevationCancelled = "cancelled"
+	ElevationExpired   = "expired"
+)
+
+// PermissionElevationRequest asks for a permission in one organization for a limited
+// time. Once approved it is backed by a UserPermission row that expires with the request.
+type PermissionElevationRequest struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	OrganizationID   uint       `json:"organization_id" gorm:"not null;index"`
+	UserID           uint       `json:"user_id" gorm:"not null;index"`
+	PermissionID     uint       `json:"permission_id" gorm:"not null;index"`
+	DurationHours    int        `json:"duration_hours" gorm:"not null"`
+	Justification    string     `json:"justification" gorm:"type:text;not null"`
+	Status           string     `json:"status" gorm:"size:20;not null;default:'pending';index;check:status IN ('pending', 'approved', 'denied', 'cancelled', 'expired')"`
+	ReviewedBy       *uint      `json:"reviewed_by" gorm:"index"`
+	ReviewedAt       *time.Time `json:"reviewed_at"`
+	ReviewNote       string     `json:"review_note" gorm:"size:500"`
+	UserPermissionID *uint      `json:"user_permission_id"`
+	ExpiresAt        *time.Time `json:"expires_at" gorm:"index"` // Set when approved
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	User       User       `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Permission Permission `json:"permission,omitempty" gorm:"foreignKey:PermissionID"`
+	Reviewer   *User      `json:"reviewer,omitempty" gorm:"foreignKey:ReviewedBy"`
+}
+
+// ExpirePermissionElevations marks approved elevations past their expiry as expired
+// and logs it on the user. The grant itself already stopped counting at ExpiresAt.
+func ExpirePermissionElevations(tx *gorm.DB) (int64, error) {
+	var elevations []PermissionElevationRequest
+	err := tx.Where("status = ? AND expires_at < ?", ElevationApproved, time.Now()).Find(&elevations).Error
+	if err != nil {
+		return 0, err
+	}
+
+	for _, elevation := range elevations {
+		err := tx.Model(&elevation).Update("status", ElevationExpired).Error
+		if err != nil {
+			return 0, err
+		}
+
+		user := User{ID: elevation.UserID}
+		user.AddHistory(tx, "ELEVATION_EXPIRED", map[string]interface{}{
+			"elevation_id":  elevation.ID,
+			"permission_id": elevation.PermissionID,
+		}, "")
+	}
+
+	return int64(len(elevations)), nil
+}
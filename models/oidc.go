@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// OIDCLoginState keeps the PKCE verifier and nonce of one single sign on attempt
+// between the redirect to the identity provider and its callback
+type OIDCLoginState struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	StateHash    string     `json:"-" gorm:"uniqueIndex;not null;size:64"` // SHA256 hash of the state parameter
+	CodeVerifier string     `json:"-" gorm:"not null;size:128"`
+	Nonce        string     `json:"-" gorm:"not null;size:64"`
+	ExpiresAt    time.Time  `json:"expires_at" gorm:"index"`
+	UsedAt       *time.Time `json:"used_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// UserIdentity links a User to an account of an external identity provider
+type UserIdentity struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	Issuer      string     `json:"issuer" gorm:"not null;size:255;uniqueIndex:idx_identity_subject,priority:1"`
+	Subject     string     `json:"subject" gorm:"not null;size:255;uniqueIndex:idx_identity_subject,priority:2"`
+	Email       string     `json:"email" gorm:"size:150"` // Email claim at the last sign in
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	User        User       `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
@@ -8,9 +8,10 @@ import (
 )
 
 type Order struct {
-	ID          uint   `json:"id" gorm:"primaryKey;autoIncrement"`
-	OrderID     string `json:"order_id" gorm:"uniqueIndex;not null;size:20"`
-	OrderNumber string `json:"order_number" gorm:"uniqueIndex;not null;size:50"`
+	ID             uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID uint   `json:"organization_id" gorm:"not null;uniqueIndex:idx_orders_org_order_id,priority:1;uniqueIndex:idx_orders_org_order_number,priority:1"`
+	OrderID        string `json:"order_id" gorm:"uniqueIndex:idx_orders_org_order_id,priority:2;not null;size:20"`
+	OrderNumber    string `json:"order_number" gorm:"uniqueIndex:idx_orders_org_order_number,priority:2;not null;size:50"`
 
 	CustomerID    uint       `json:"customer_id" gorm:"not null;index" binding:"required"`
 	CustomerNotes string     `json:"customer_notes" gorm:"type:text"`
@@ -45,13 +46,13 @@ type Order struct {
 	PaymentMethod    string `json:"payment_method" gorm:"not null;size:50" binding:"required"`
 	PaymentReference string `json:"payment_reference" gorm:"size:100"`
 
-	Subtotal       float64 `json:"subtotal" gorm:"type:decimal(12,2);not null;default:0"`
-	TaxRate        float64 `json:"tax_rate" gorm:"type:decimal(5,4);default:0"`
-	TaxAmount      float64 `json:"tax_amount" gorm:"type:decimal(12,2);default:0"`
-	ShippingCost   float64 `json:"shipping_cost" gorm:"type:decimal(12,2);default:0"`
-	DiscountAmount float64 `json:"discount_amount" gorm:"type:decimal(12,2);default:0"`
-	TotalAmount    float64 `json:"total_amount" gorm:"type:decimal(12,2);not null"`
-	Currency       string  `json:"currency" gorm:"size:3;default:'USD'"`
+	Subtotal       float64  `json:"subtotal" gorm:"type:decimal(12,2);not null;default:0"`
+	TaxRate        *float64 `json:"tax_rate" gorm:"type:decimal(5,4);default:0"` // nil takes the organization's default, 0 is tax exempt
+	TaxAmount      float64  `json:"tax_amount" gorm:"type:decimal(12,2);default:0"`
+	ShippingCost   float64  `json:"shipping_cost" gorm:"type:decimal(12,2);default:0"`
+	DiscountAmount float64  `json:"discount_amount" gorm:"type:decimal(12,2);default:0"`
+	TotalAmount    float64  `json:"total_amount" gorm:"type:decimal(12,2);not null"`
+	Currency       string   `json:"currency" gorm:"size:3;default:'USD'"`
 
 	TrackingNumber  string `json:"tracking_number" gorm:"size:100"`
 	ShippingMethod  string `json:"shipping_method" gorm:"size:100"`
@@ -78,6 +79,7 @@ type Order struct {
 
 type OrderItem struct {
 	ID               uint     `json:"id" gorm:"primaryKey"`
+	OrganizationID   uint     `json:"organization_id" gorm:"not null;index"`
 	OrderID          uint     `json:"order_id" gorm:"not null;index"`
 	ProductID        uint     `json:"product_id" gorm:"not null;index"`
 	ProductVariantID *uint    `json:"product_variant_id" gorm:"index"`
@@ -103,14 +105,15 @@ type OrderItem struct {
 }
 
 type OrderHistory struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	OrderID     uint      `json:"order_id" gorm:"not null;index"`
-	Action      string    `json:"action" gorm:"not null;size:50"`
-	OldValue    string    `json:"old_value" gorm:"size:200"`
-	NewValue    string    `json:"new_value" gorm:"size:200"`
-	Description string    `json:"description" gorm:"size:500"`
-	PerformedBy uint      `json:"performed_by" gorm:"not null;index"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"not null;index"`
+	OrderID        uint      `json:"order_id" gorm:"not null;index"`
+	Action         string    `json:"action" gorm:"not null;size:50"`
+	OldValue       string    `json:"old_value" gorm:"size:200"`
+	NewValue       string    `json:"new_value" gorm:"size:200"`
+	Description    string    `json:"description" gorm:"size:500"`
+	PerformedBy    uint      `json:"performed_by" gorm:"not null;index"`
+	CreatedAt      time.Time `json:"created_at"`
 
 	Order           Order `json:"order,omitempty" gorm:"foreignKey:OrderID"`
 	PerformedByUser User  `json:"performed_by_user,omitempty" gorm:"foreignKey:PerformedBy"`
@@ -118,6 +121,7 @@ type OrderHistory struct {
 
 type OrderPayment struct {
 	ID              uint       `json:"id" gorm:"primaryKey"`
+	OrganizationID  uint       `json:"organization_id" gorm:"not null;index"`
 	OrderID         uint       `json:"order_id" gorm:"not null;index"`
 	PaymentMethod   string     `json:"payment_method" gorm:"not null;size:50"`
 	PaymentProvider string     `json:"payment_provider" gorm:"size:50"` // Stripe, PayPal, etc.
@@ -136,6 +140,7 @@ type OrderPayment struct {
 
 type OrderShipment struct {
 	ID             uint     `json:"id" gorm:"primaryKey"`
+	OrganizationID uint     `json:"organization_id" gorm:"not null;index"`
 	OrderID        uint     `json:"order_id" gorm:"not null;index"`
 	TrackingNumber string   `json:"tracking_number" gorm:"size:100"`
 	Carrier        string   `json:"carrier" gorm:"size:100"`
@@ -157,22 +162,24 @@ type OrderShipment struct {
 }
 
 type OrderShipmentItem struct {
-	ID          uint `json:"id" gorm:"primaryKey"`
-	ShipmentID  uint `json:"shipment_id" gorm:"not null;index"`
-	OrderItemID uint `json:"order_item_id" gorm:"not null;index"`
-	Quantity    int  `json:"quantity" gorm:"not null"`
+	ID             uint `json:"id" gorm:"primaryKey"`
+	OrganizationID uint `json:"organization_id" gorm:"not null;index"`
+	ShipmentID     uint `json:"shipment_id" gorm:"not null;index"`
+	OrderItemID    uint `json:"order_item_id" gorm:"not null;index"`
+	Quantity       int  `json:"quantity" gorm:"not null"`
 
 	Shipment  OrderShipment `json:"shipment,omitempty" gorm:"foreignKey:ShipmentID"`
 	OrderItem OrderItem     `json:"order_item,omitempty" gorm:"foreignKey:OrderItemID"`
 }
 
 type Customer struct {
-	ID         uint   `json:"id" gorm:"primaryKey"`
-	CustomerID string `json:"customer_id" gorm:"uniqueIndex;not null;size:20"`
+	ID             uint   `json:"id" gorm:"primaryKey"`
+	OrganizationID uint   `json:"organization_id" gorm:"not null;uniqueIndex:idx_customers_org_customer_id,priority:1;uniqueIndex:idx_customers_org_email,priority:1"`
+	CustomerID     string `json:"customer_id" gorm:"uniqueIndex:idx_customers_org_customer_id,priority:2;not null;size:20"`
 
 	FirstName      string `json:"first_name" gorm:"not null;size:100" binding:"required"`
 	LastName       string `json:"last_name" gorm:"not null;size:100" binding:"required"`
-	Email          string `json:"email" gorm:"uniqueIndex;not null;size:150" binding:"required,email"`
+	Email          string `json:"email" gorm:"uniqueIndex:idx_customers_org_email,priority:2;not null;size:150" binding:"required,email"`
 	Phone          string `json:"phone" gorm:"size:20"`
 	SecondaryPhone string `json:"secondary_phone" gorm:"size:20"`
 	Company        string `json:"company" gorm:"size:200"`
@@ -211,12 +218,23 @@ type Customer struct {
 }
 
 func (o *Order) BeforeCreate(tx *gorm.DB) error {
+	settings := SettingsFor(tx)
+
 	if o.OrderID == "" {
-		o.OrderID = generateOrderID(tx)
+		o.OrderID = generateOrderID(tx, settings.Location())
 	}
 
 	if o.OrderNumber == "" {
-		o.OrderNumber = generateOrderNumber(tx)
+		o.OrderNumber = generateOrderNumber(tx, settings.OrderNumberPrefix)
+	}
+
+	if o.Currency == "" {
+		o.Currency = settings.BaseCurrency
+	}
+
+	if o.TaxRate == nil {
+		taxRate := settings.DefaultTaxRate
+		o.TaxRate = &taxRate
 	}
 
 	return nil
@@ -251,7 +269,10 @@ func (o *Order) CalculateTotals(tx *gorm.DB) error {
 	}
 
 	o.Subtotal = subtotal
-	o.TaxAmount = subtotal * o.TaxRate
+	o.TaxAmount = 0
+	if o.TaxRate != nil {
+		o.TaxAmount = subtotal * *o.TaxRate
+	}
 	o.TotalAmount = o.Subtotal + o.TaxAmount + o.ShippingCost - o.DiscountAmount
 
 	return tx.Save(o).Error
@@ -394,10 +415,10 @@ func (oi *OrderItem) updateOrderTotals(tx *gorm.DB) error {
 
 // Helper functions
 
-// generateOrderID generates a unique order ID
-func generateOrderID(tx *gorm.DB) string {
+// generateOrderID generates a unique order ID dated in the organization's time zone
+func generateOrderID(tx *gorm.DB, location *time.Location) string {
 	for {
-		orderID := fmt.Sprintf("ORD-%s", time.Now().Format("20060102")) + fmt.Sprintf("-%04d", time.Now().Unix()%10000)
+		orderID := fmt.Sprintf("ORD-%s", time.Now().In(location).Format("20060102")) + fmt.Sprintf("-%04d", time.Now().Unix()%10000)
 		var count int64
 		tx.Model(&Order{}).Where("order_id = ?", orderID).Count(&count)
 		if count == 0 {
@@ -406,10 +427,10 @@ func generateOrderID(tx *gorm.DB) string {
 	}
 }
 
-// generateOrderNumber generates a human-readable order number
-func generateOrderNumber(tx *gorm.DB) string {
+// generateOrderNumber generates a human-readable order number after the organization's prefix
+func generateOrderNumber(tx *gorm.DB, prefix string) string {
 	for {
-		orderNumber := fmt.Sprintf("ORD-%06d", time.Now().Unix()%1000000)
+		orderNumber := fmt.Sprintf("%s-%06d", prefix, time.Now().Unix()%1000000)
 		var count int64
 		tx.Model(&Order{}).Where("order_number = ?", orderNumber).Count(&count)
 		if count == 0 {
@@ -436,6 +457,9 @@ func (c *Customer) BeforeCreate(tx *gorm.DB) error {
 	if c.CustomerID == "" {
 		c.CustomerID = generateCustomerID(tx)
 	}
+	if c.Country == "" {
+		c.Country = SettingsFor(tx).Country
+	}
 	return nil
 }
 
@@ -1,6 +1,28 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/farhapartex/ainventory/tenant"
+	"gorm.io/gorm"
+)
+
+// Roles a user can hold within one organization. They decide who manages the
+// organization and its members, the permissions of a member come from RoleID.
+const (
+	OrganizationRoleOwner  = "owner"
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
+// Invitation states. An invitation past its expiry counts as expired even before
+// the cleanup job updates its status.
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusExpired  = "expired"
+	InvitationStatusRevoked  = "revoked"
+)
 
 type Organization struct {
 	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
@@ -10,9 +32,140 @@ type Organization struct {
 	State     string    `json:"state" gorm:"size:100"`
 	ZipCode   string    `json:"zip_code" gorm:"size:20"`
 	Country   string    `json:"country" gorm:"size:100;default:'United States'"`
-	OwnerID   uint      `json:"owner_id" gorm:"not null"`
+	OwnerID   uint      `json:"owner_id" gorm:"not null"` // User who created the organization, ownership itself is an OrganizationMember role
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
-	Owner User `json:"owner" gorm:"foreignKey:OwnerID;references:ID"`
+	PasswordMaxAgeDays int `json:"password_max_age_days" gorm:"default:0"` // 0 means passwords never expire
+
+	Owner   User                 `json:"owner" gorm:"foreignKey:OwnerID;references:ID"`
+	Members []OrganizationMember `json:"members,omitempty" gorm:"foreignKey:OrganizationID"`
+}
+
+// OrganizationMember links a user to an organization with an organization scoped role
+// and the Role whose permissions the user holds within the organization
+type OrganizationMember struct {
+	ID             uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID uint      `json:"organization_id" gorm:"not null;uniqueIndex:idx_org_member"`
+	UserID         uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_org_member;index"`
+	Role           string    `json:"role" gorm:"size:20;not null;default:'member'"` // owner, admin or member
+	RoleID         *uint     `json:"role_id" gorm:"index"`                          // Permission role within this organization
+	InvitedBy      *uint     `json:"invited_by"`
+	CreatedAt      time.Time `json:"created_at"` // When the user joined
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	Organization Organization `json:"organization,omitempty" gorm:"foreignKey:OrganizationID"`
+	User         User         `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// OrganizationInvitation is an emailed, single use invitation to join an organization
+type OrganizationInvitation struct {
+	ID             uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID uint       `json:"organization_id" gorm:"not null;index"`
+	Email          string     `json:"email" gorm:"size:255;not null;index"`
+	Role           string     `json:"role" gorm:"size:20;not null"`
+	TokenHash      string     `json:"-" gorm:"uniqueIndex;not null;size:64"` // SHA256 of the emailed token
+	Status         string     `json:"status" gorm:"size:20;not null;default:'pending';index"`
+	ExpiresAt      time.Time  `json:"expires_at" gorm:"index"`
+	InvitedBy      uint       `json:"invited_by" gorm:"not null"`
+	AcceptedBy     *uint      `json:"accepted_by"`
+	AcceptedAt     *time.Time `json:"accepted_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	Organization Organization `json:"organization,omitempty" gorm:"foreignKey:OrganizationID"`
+	Inviter      User         `json:"inviter,omitempty" gorm:"foreignKey:InvitedBy"`
+}
+
+func IsValidOrganizationRole(role string) bool {
+	switch role {
+	case OrganizationRoleOwner, OrganizationRoleAdmin, OrganizationRoleMember:
+		return true
+	}
+	return false
+}
+
+// BeforeCreate fills the country from the default settings, a new organization
+// has none of its own yet
+func (o *Organization) BeforeCreate(tx *gorm.DB) error {
+	if o.Country == "" {
+		o.Country = DefaultOrganizationSettings().Country
+	}
+	return nil
+}
+
+// CanManage reports whether the member may invite, remove and change other members
+func (m *OrganizationMember) CanManage() bool {
+	return m.Role == OrganizationRoleOwner || m.Role == OrganizationRoleAdmin
+}
+
+// EffectiveStatus is the status of the invitation, taking its expiry into account
+func (i *OrganizationInvitation) EffectiveStatus() string {
+	if i.Status == InvitationStatusPending && time.Now().After(i.ExpiresAt) {
+		return InvitationStatusExpired
+	}
+	return i.Status
+}
+
+// DefaultOrganizationID is the organization the user joined first, 0 without memberships
+func (u *User) DefaultOrganizationID(tx *gorm.DB) uint {
+	var membership OrganizationMember
+	tx.Where("user_id = ?", u.ID).Order("created_at ASC, id ASC").Limit(1).Find(&membership)
+	return membership.OrganizationID
+}
+
+// Membership returns the membership of the user in the organization active on tx,
+// nil without an active organization or when the user is not a member of it
+func (u *User) Membership(tx *gorm.DB) *OrganizationMember {
+	organizationID, ok := tenant.OrganizationID(tx.Statement.Context)
+	if !ok {
+		return nil
+	}
+
+	var membership OrganizationMember
+	if tx.Where("organization_id = ? AND user_id = ?", organizationID, u.ID).Limit(1).Find(&membership).RowsAffected == 0 {
+		return nil
+	}
+	return &membership
+}
+
+// AssignRole moves the member to another permission role, nil removes the role,
+// and keeps Role.UserCount of both roles correct
+func (m *OrganizationMember) AssignRole(tx *gorm.DB, roleID *uint) error {
+	previousRoleID := m.RoleID
+	if err := tx.Model(m).Update("role_id", roleID).Error; err != nil {
+		return err
+	}
+	m.RoleID = roleID
+
+	for _, id := range []*uint{previousRoleID, roleID} {
+		if id == nil {
+			continue
+		}
+		role := Role{ID: *id}
+		if err := role.UpdateUserCount(tx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExpireOrganizationInvitations marks pending invitations past their expiry as expired
+func ExpireOrganizationInvitations(tx *gorm.DB) (int64, error) {
+	result := tx.Model(&OrganizationInvitation{}).
+		Where("status = ? AND expires_at < ?", InvitationStatusPending, time.Now()).
+		Update("status", InvitationStatusExpired)
+	return result.RowsAffected, result.Error
+}
+
+// BackfillOrganizationOwners makes the creator of every organization without
+// members its owner. Organizations created before memberships existed only had OwnerID.
+func BackfillOrganizationOwners(tx *gorm.DB) error {
+	return tx.Exec(`
+		INSERT INTO organization_members (organization_id, user_id, role, created_at, updated_at)
+		SELECT o.id, o.owner_id, ?, o.created_at, NOW()
+		FROM organizations o
+		WHERE NOT EXISTS (SELECT 1 FROM organization_members m WHERE m.organization_id = o.id)
+		ON CONFLICT DO NOTHING`, OrganizationRoleOwner).Error
 }
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ExportStatusPending   = "pending"
+	ExportStatusRunning   = "running"
+	ExportStatusCompleted = "completed"
+	ExportStatusFailed    = "failed"
+)
+
+// OrganizationExport is an archive of everything that belongs to an organization,
+// built in the background so the data can be handed over before it is purged
+type OrganizationExport struct {
+	ID             uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID uint       `json:"organization_id" gorm:"not null;index"`
+	RequestedBy    uint       `json:"requested_by" gorm:"not null;index"`
+	Status         string     `json:"status" gorm:"size:20;not null;default:'pending';check:status IN ('pending', 'running', 'completed', 'failed')"`
+	FilePath       string     `json:"-" gorm:"size:500"`
+	FileSize       int64      `json:"file_size" gorm:"default:0"`
+	Checksum       string     `json:"checksum" gorm:"size:64"` // SHA256 of the archive
+	Error          string     `json:"error" gorm:"type:text"`
+	StartedAt      *time.Time `json:"started_at"`
+	CompletedAt    *time.Time `json:"completed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// IsActive reports whether the export is still being built
+func (e *OrganizationExport) IsActive() bool {
+	return e.Status == ExportStatusPending || e.Status == ExportStatusRunning
+}
+
+// FailInterruptedExports marks exports that were being built when the server
+// stopped as failed, nothing resumes them
+func FailInterruptedExports(tx *gorm.DB) (int64, error) {
+	result := tx.Model(&OrganizationExport{}).
+		Where("status IN ?", []string{ExportStatusPending, ExportStatusRunning}).
+		Updates(map[string]interface{}{
+			"status":       ExportStatusFailed,
+			"error":        "interrupted by a server restart",
+			"completed_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
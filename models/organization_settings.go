@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/farhapartex/ainventory/tenant"
+	"gorm.io/gorm"
+)
+
+const (
+	UnitSystemMetric   = "metric"
+	UnitSystemImperial = "imperial"
+)
+
+// OrganizationSettings holds the defaults new business records of an organization
+// inherit. Organizations without a row use DefaultOrganizationSettings.
+type OrganizationSettings struct {
+	ID                       uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID           uint      `json:"organization_id" gorm:"not null;uniqueIndex"`
+	BaseCurrency             string    `json:"base_currency" gorm:"size:3;not null;default:'USD'"`           // ISO 4217
+	Timezone                 string    `json:"timezone" gorm:"size:64;not null;default:'UTC'"`               // IANA name
+	Country                  string    `json:"country" gorm:"size:100;not null;default:'United States'"`     // Default for customers and suppliers
+	DefaultTaxRate           float64   `json:"default_tax_rate" gorm:"type:decimal(5,4);not null;default:0"` // Fraction, 0.0825 is 8.25%
+	DefaultLowStockThreshold int       `json:"default_low_stock_threshold" gorm:"not null;default:10"`
+	SKUPrefix                string    `json:"sku_prefix" gorm:"size:10;not null;default:'SKU'"`
+	OrderNumberPrefix        string    `json:"order_number_prefix" gorm:"size:10;not null;default:'ORD'"`
+	UnitSystem               string    `json:"unit_system" gorm:"size:10;not null;default:'metric';check:unit_system IN ('metric', 'imperial')"`
+	UpdatedBy                *uint     `json:"updated_by"`
+	CreatedAt                time.Time `json:"created_at"`
+	UpdatedAt                time.Time `json:"updated_at"`
+}
+
+func DefaultOrganizationSettings() OrganizationSettings {
+	return OrganizationSettings{
+		BaseCurrency:             "USD",
+		Timezone:                 "UTC",
+		Country:                  "United States",
+		DefaultTaxRate:           0,
+		DefaultLowStockThreshold: 10,
+		SKUPrefix:                "SKU",
+		OrderNumberPrefix:        "ORD",
+		UnitSystem:               UnitSystemMetric,
+	}
+}
+
+// SettingsFor returns the settings of the organization active on tx, or the
+// defaults when it has not changed any or tx has no active organization
+func SettingsFor(tx *gorm.DB) OrganizationSettings {
+	if _, ok := tenant.OrganizationID(tx.Statement.Context); !ok {
+		return DefaultOrganizationSettings()
+	}
+
+	var settings OrganizationSettings
+	if tx.Limit(1).Find(&settings).RowsAffected == 0 {
+		return DefaultOrganizationSettings()
+	}
+	return settings
+}
+
+// Location is the time zone the organization's dates, like order numbers, are based on
+func (s *OrganizationSettings) Location() *time.Location {
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
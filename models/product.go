@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -8,8 +9,9 @@ import (
 
 type Product struct {
 	ID               uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID   uint   `json:"organization_id" gorm:"not null;uniqueIndex:idx_products_org_sku,priority:1"`
 	Name             string `json:"name" gorm:"not null;size:200;index" binding:"required"`
-	SKU              string `json:"sku" gorm:"uniqueIndex;not null;size:50" binding:"required"`
+	SKU              string `json:"sku" gorm:"uniqueIndex:idx_products_org_sku,priority:2;not null;size:50" binding:"required"`
 	Description      string `json:"description" gorm:"type:text" binding:"required"`
 	ShortDescription string `json:"short_description" gorm:"size:500"`
 
@@ -64,15 +66,16 @@ type Product struct {
 }
 
 type ProductCategory struct {
-	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
-	Name        string    `json:"name" gorm:"uniqueIndex;not null;size:100" binding:"required"`
-	Code        string    `json:"code" gorm:"uniqueIndex;not null;size:20" binding:"required"`
-	Description string    `json:"description" gorm:"size:500"`
-	ParentID    *uint     `json:"parent_id" gorm:"index"`
-	SortOrder   int       `json:"sort_order" gorm:"default:0"`
-	IsActive    bool      `json:"is_active" gorm:"default:true"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID             uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID uint      `json:"organization_id" gorm:"not null;uniqueIndex:idx_product_categories_org_name,priority:1;uniqueIndex:idx_product_categories_org_code,priority:1"`
+	Name           string    `json:"name" gorm:"uniqueIndex:idx_product_categories_org_name,priority:2;not null;size:100" binding:"required"`
+	Code           string    `json:"code" gorm:"uniqueIndex:idx_product_categories_org_code,priority:2;not null;size:20" binding:"required"`
+	Description    string    `json:"description" gorm:"size:500"`
+	ParentID       *uint     `json:"parent_id" gorm:"index"`
+	SortOrder      int       `json:"sort_order" gorm:"default:0"`
+	IsActive       bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 
 	Parent   *ProductCategory  `json:"parent,omitempty" gorm:"foreignKey:ParentID"`
 	Children []ProductCategory `json:"children,omitempty" gorm:"foreignKey:ParentID"`
@@ -80,36 +83,39 @@ type ProductCategory struct {
 }
 
 type ProductImage struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	ProductID uint      `json:"product_id" gorm:"not null;index"`
-	URL       string    `json:"url" gorm:"not null;size:500" binding:"required"`
-	AltText   string    `json:"alt_text" gorm:"size:200"`
-	SortOrder int       `json:"sort_order" gorm:"default:0"`
-	IsMain    bool      `json:"is_main" gorm:"default:false"`
-	FileSize  *int64    `json:"file_size"` // in bytes
-	MimeType  string    `json:"mime_type" gorm:"size:50"`
-	CreatedAt time.Time `json:"created_at"`
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"not null;index"`
+	ProductID      uint      `json:"product_id" gorm:"not null;index"`
+	URL            string    `json:"url" gorm:"not null;size:500" binding:"required"`
+	AltText        string    `json:"alt_text" gorm:"size:200"`
+	SortOrder      int       `json:"sort_order" gorm:"default:0"`
+	IsMain         bool      `json:"is_main" gorm:"default:false"`
+	FileSize       *int64    `json:"file_size"` // in bytes
+	MimeType       string    `json:"mime_type" gorm:"size:50"`
+	CreatedAt      time.Time `json:"created_at"`
 
 	Product Product `json:"product,omitempty" gorm:"foreignKey:ProductID"`
 }
 
 type ProductVariant struct {
-	ID         uint      `json:"id" gorm:"primaryKey"`
-	ProductID  uint      `json:"product_id" gorm:"not null;index"`
-	Name       string    `json:"name" gorm:"not null;size:100" binding:"required"`
-	SKU        string    `json:"sku" gorm:"uniqueIndex;not null;size:50" binding:"required"`
-	Price      *float64  `json:"price" gorm:"type:decimal(12,2)"`
-	Quantity   int       `json:"quantity" gorm:"default:0"`
-	Attributes string    `json:"attributes" gorm:"type:text"`
-	IsActive   bool      `json:"is_active" gorm:"default:true"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"not null;uniqueIndex:idx_product_variants_org_sku,priority:1"`
+	ProductID      uint      `json:"product_id" gorm:"not null;index"`
+	Name           string    `json:"name" gorm:"not null;size:100" binding:"required"`
+	SKU            string    `json:"sku" gorm:"uniqueIndex:idx_product_variants_org_sku,priority:2;not null;size:50" binding:"required"`
+	Price          *float64  `json:"price" gorm:"type:decimal(12,2)"`
+	Quantity       int       `json:"quantity" gorm:"default:0"`
+	Attributes     string    `json:"attributes" gorm:"type:text"`
+	IsActive       bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 
 	Product Product `json:"product,omitempty" gorm:"foreignKey:ProductID"`
 }
 
 type InventoryTransaction struct {
 	ID               uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID   uint      `json:"organization_id" gorm:"not null;index"`
 	ProductID        uint      `json:"product_id" gorm:"not null;index"`
 	ProductVariantID *uint     `json:"product_variant_id" gorm:"index"`
 	Type             string    `json:"type" gorm:"not null;size:20;check:type IN ('purchase', 'sale', 'adjustment', 'return', 'transfer', 'damaged', 'expired')"`
@@ -128,23 +134,25 @@ type InventoryTransaction struct {
 }
 
 type ProductPriceHistory struct {
-	ID            uint      `json:"id" gorm:"primaryKey"`
-	ProductID     uint      `json:"product_id" gorm:"not null;index"`
-	OldPrice      *float64  `json:"old_price" gorm:"type:decimal(12,2)"`
-	NewPrice      float64   `json:"new_price" gorm:"type:decimal(12,2);not null"`
-	OldCost       *float64  `json:"old_cost" gorm:"type:decimal(12,2)"`
-	NewCost       *float64  `json:"new_cost" gorm:"type:decimal(12,2)"`
-	Reason        string    `json:"reason" gorm:"size:200"`
-	ChangedBy     uint      `json:"changed_by" gorm:"not null;index"`
-	CreatedAt     time.Time `json:"created_at"`
-	Product       Product   `json:"product,omitempty" gorm:"foreignKey:ProductID"`
-	ChangedByUser User      `json:"changed_by_user,omitempty" gorm:"foreignKey:ChangedBy"`
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"not null;index"`
+	ProductID      uint      `json:"product_id" gorm:"not null;index"`
+	OldPrice       *float64  `json:"old_price" gorm:"type:decimal(12,2)"`
+	NewPrice       float64   `json:"new_price" gorm:"type:decimal(12,2);not null"`
+	OldCost        *float64  `json:"old_cost" gorm:"type:decimal(12,2)"`
+	NewCost        *float64  `json:"new_cost" gorm:"type:decimal(12,2)"`
+	Reason         string    `json:"reason" gorm:"size:200"`
+	ChangedBy      uint      `json:"changed_by" gorm:"not null;index"`
+	CreatedAt      time.Time `json:"created_at"`
+	Product        Product   `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	ChangedByUser  User      `json:"changed_by_user,omitempty" gorm:"foreignKey:ChangedBy"`
 }
 
 type Supplier struct {
 	ID                uint           `json:"id" gorm:"primaryKey"`
+	OrganizationID    uint           `json:"organization_id" gorm:"not null;uniqueIndex:idx_suppliers_org_code,priority:1"`
 	Name              string         `json:"name" gorm:"not null;size:200;index" binding:"required"`
-	Code              string         `json:"code" gorm:"uniqueIndex;not null;size:20" binding:"required"`
+	Code              string         `json:"code" gorm:"uniqueIndex:idx_suppliers_org_code,priority:2;not null;size:20" binding:"required"`
 	ContactPerson     string         `json:"contact_person" gorm:"size:100"`
 	Email             string         `json:"email" gorm:"size:100;index"`
 	Phone             string         `json:"phone" gorm:"size:20"`
@@ -172,6 +180,7 @@ type Supplier struct {
 
 type ProductReview struct {
 	ID             uint       `json:"id" gorm:"primaryKey"`
+	OrganizationID uint       `json:"organization_id" gorm:"not null;index"`
 	ProductID      uint       `json:"product_id" gorm:"not null;index"`
 	CustomerID     uint       `json:"customer_id" gorm:"not null;index"`
 	Rating         int        `json:"rating" gorm:"not null;check:rating >= 1 AND rating <= 5"`
@@ -190,6 +199,18 @@ type ProductReview struct {
 // Model hooks and methods
 
 func (p *Product) BeforeCreate(tx *gorm.DB) error {
+	settings := SettingsFor(tx)
+	if p.SKU == "" {
+		p.SKU = generateSKU(tx, settings.SKUPrefix)
+	}
+	if p.Currency == "" {
+		p.Currency = settings.BaseCurrency
+	}
+	if p.LowStockThreshold == nil {
+		threshold := settings.DefaultLowStockThreshold
+		p.LowStockThreshold = &threshold
+	}
+
 	// Update stock status based on quantity
 	p.updateStockStatus()
 	return nil
@@ -291,6 +312,32 @@ func (p *Product) CalculateProfitMargin() float64 {
 	return ((p.Price - p.Cost) / p.Cost) * 100
 }
 
+func (s *Supplier) BeforeCreate(tx *gorm.DB) error {
+	settings := SettingsFor(tx)
+	if s.Currency == "" {
+		s.Currency = settings.BaseCurrency
+	}
+	if s.Country == "" {
+		s.Country = settings.Country
+	}
+	return nil
+}
+
+// generateSKU numbers products after the organization's SKU prefix
+func generateSKU(tx *gorm.DB, prefix string) string {
+	var count int64
+	tx.Unscoped().Model(&Product{}).Count(&count)
+	for {
+		count++
+		sku := fmt.Sprintf("%s-%06d", prefix, count)
+		var existing int64
+		tx.Unscoped().Model(&Product{}).Where("sku = ?", sku).Count(&existing)
+		if existing == 0 {
+			return sku
+		}
+	}
+}
+
 func (pi *ProductImage) BeforeCreate(tx *gorm.DB) error {
 	if pi.IsMain {
 		tx.Model(&ProductImage{}).Where("product_id = ? AND is_main = ?", pi.ProductID, true).Update("is_main", false)
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RateLimitBucket is one token bucket of the Postgres rate limiter backend
+type RateLimitBucket struct {
+	Key       string    `json:"key" gorm:"primaryKey;size:200"` // Policy name and client, like "auth:ip:10.0.0.1"
+	Tokens    float64   `json:"tokens" gorm:"type:double precision;not null"`
+	Allowed   bool      `json:"allowed" gorm:"not null"` // Outcome of the last request
+	UpdatedAt time.Time `json:"updated_at" gorm:"index"`
+}
+
+// PurgeIdleRateLimitBuckets removes buckets untouched since before, a missing
+// bucket behaves like a full one
+func PurgeIdleRateLimitBuckets(tx *gorm.DB, before time.Time) (int64, error) {
+	result := tx.Where("updated_at < ?", before).Delete(&RateLimitBucket{})
+	return result.RowsAffected, result.Error
+}
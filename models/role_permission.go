@@ -1,11 +1,20 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// MaxRoleLevel is the highest Role.Level
+const MaxRoleLevel = 5
+
+var (
+	ErrSystemRole = errors.New("system roles cannot be deleted")
+	ErrRoleInUse  = errors.New("role is still assigned to users")
+)
+
 type Permission struct {
 	ID          uint           `json:"id" gorm:"primaryKey;autoIncrement"`
 	Name        string         `json:"name" gorm:"uniqueIndex;not null;size:100" binding:"required"`
@@ -22,6 +31,8 @@ type Permission struct {
 	Roles []Role `json:"roles,omitempty" gorm:"many2many:role_permissions;"`
 }
 
+// Role is a set of permissions shared by every organization. Members hold one role
+// per organization through OrganizationMember.RoleID.
 type Role struct {
 	ID          uint           `json:"id" gorm:"primaryKey;autoIncrement"`
 	Name        string         `json:"name" gorm:"uniqueIndex;not null;size:100" binding:"required"`
@@ -29,19 +40,21 @@ type Role struct {
 	Description string         `json:"description" gorm:"size:500" binding:"required"`
 	Level       int            `json:"level" gorm:"not null;default:1;check:level >= 0 AND level <= 5" binding:"required,min=0,max=5"`
 	Color       string         `json:"color" gorm:"size:20;default:'blue'" binding:"required"`
-	IsDefault   bool           `json:"is_default" gorm:"default:false"`
+	IsDefault   bool           `json:"is_default" gorm:"default:false"` // Given to members joining an organization by invitation
 	IsSystem    bool           `json:"is_system" gorm:"default:false"`
 	IsActive    bool           `json:"is_active" gorm:"default:true"`
-	UserCount   int            `json:"user_count" gorm:"default:0"`
+	UserCount   int            `json:"user_count" gorm:"default:0"` // Memberships holding the role
 	CreatedBy   uint           `json:"created_by" gorm:"index"`
+	TemplateID  *uint          `json:"template_id" gorm:"index"` // Linked template, its later edits are applied to the role
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 
 	// Relationships
-	Permissions []Permission `json:"permissions,omitempty" gorm:"many2many:role_permissions;"`
-	Users       []User       `json:"users,omitempty" gorm:"foreignKey:RoleID"`
-	Creator     *User        `json:"creator,omitempty" gorm:"foreignKey:CreatedBy"`
+	Permissions []Permission         `json:"permissions,omitempty" gorm:"many2many:role_permissions;"`
+	Members     []OrganizationMember `json:"members,omitempty" gorm:"foreignKey:RoleID"`
+	Creator     *User                `json:"creator,omitempty" gorm:"foreignKey:CreatedBy"`
+	Template    *PermissionTemplate  `json:"template,omitempty" gorm:"foreignKey:TemplateID"`
 }
 
 // RolePermission represents the many-to-many relationship between roles and permissions
@@ -122,21 +135,31 @@ func (r *Role) BeforeUpdate(tx *gorm.DB) error {
 
 func (r *Role) BeforeDelete(tx *gorm.DB) error {
 	if r.IsSystem {
-		return gorm.ErrRecordNotFound // Or custom error
+		return ErrSystemRole
 	}
 
 	var userCount int64
-	tx.Model(&User{}).Where("role_id = ?", r.ID).Count(&userCount)
+	tx.Model(&OrganizationMember{}).Where("role_id = ?", r.ID).Count(&userCount)
 	if userCount > 0 {
-		return gorm.ErrRecordNotFound // Or custom error: cannot delete role with users
+		return ErrRoleInUse
 	}
 
 	return nil
 }
 
+// DefaultRoleID returns the id of the active default role, nil when there is none
+func DefaultRoleID(tx *gorm.DB) *uint {
+	var role Role
+	if tx.Where("is_default = ? AND is_active = ?", true, true).Limit(1).Find(&role).RowsAffected == 0 {
+		return nil
+	}
+	return &role.ID
+}
+
+// UpdateUserCount recounts the memberships holding the role, across all organizations
 func (r *Role) UpdateUserCount(tx *gorm.DB) error {
 	var count int64
-	tx.Model(&User{}).Where("role_id = ?", r.ID).Count(&count)
+	tx.Model(&OrganizationMember{}).Where("role_id = ?", r.ID).Count(&count)
 	return tx.Model(r).Update("user_count", count).Error
 }
 
@@ -167,6 +190,20 @@ func (r *Role) GetPermissionsByModule(tx *gorm.DB) (map[string][]Permission, err
 	return modulePermissions, nil
 }
 
+// PermissionIDs returns the ids of the permissions the role holds
+func (r *Role) PermissionIDs(tx *gorm.DB) []uint {
+	var ids []uint
+	tx.Model(&RolePermission{}).Where("role_id = ?", r.ID).Pluck("permission_id", &ids)
+	return ids
+}
+
+// PermissionIDs returns the ids of the permissions bundled in the template
+func (t *PermissionTemplate) PermissionIDs(tx *gorm.DB) []uint {
+	var ids []uint
+	tx.Model(&TemplatePermission{}).Where("template_id = ?", t.ID).Pluck("permission_id", &ids)
+	return ids
+}
+
 func (p *Permission) IsValidAction() bool {
 	validActions := []string{"view", "create", "edit", "delete", "manage", "export", "import", "approve"}
 	for _, action := range validActions {
@@ -221,6 +258,9 @@ func GetDefaultPermissions() []Permission {
 		{Name: "reports.view", DisplayName: "View Reports", Module: "reports", Action: "view", Description: "Access to reports"},
 		{Name: "reports.export", DisplayName: "Export Reports", Module: "reports", Action: "export", Description: "Export report data"},
 
+		// Audit permissions
+		{Name: "audit.view", DisplayName: "View Audit Log", Module: "audit", Action: "view", Description: "Review the audit trail of user actions"},
+
 		// Settings permissions
 		{Name: "settings.view", DisplayName: "View Settings", Module: "settings", Action: "view", Description: "View system settings"},
 		{Name: "settings.edit", DisplayName: "Edit Settings", Module: "settings", Action: "edit", Description: "Modify system settings"},
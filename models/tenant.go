@@ -0,0 +1,369 @@
+package models
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/farhapartex/ainventory/tenant"
+	"gorm.io/gorm"
+)
+
+// TenantModels are the business models whose rows belong to one organization
+func TenantModels() []interface{} {
+	return []interface{}{
+		&Department{},
+		&DepartmentRole{},
+		&DepartmentHierarchy{},
+		&DepartmentBudget{},
+		&DepartmentPermission{},
+		&DepartmentHistory{},
+		&Customer{},
+		&ProductCategory{},
+		&Supplier{},
+		&Product{},
+		&ProductImage{},
+		&ProductVariant{},
+		&InventoryTransaction{},
+		&ProductPriceHistory{},
+		&ProductReview{},
+		&Order{},
+		&OrderItem{},
+		&OrderHistory{},
+		&OrderPayment{},
+		&OrderShipment{},
+		&OrderShipmentItem{},
+		&OrganizationSettings{},
+	}
+}
+
+// globalUniqueIndexes were unique across all organizations before tenants were
+// introduced, they are replaced by per organization indexes
+var globalUniqueIndexes = []string{
+	"idx_departments_name",
+	"idx_departments_code",
+	"idx_customers_customer_id",
+	"idx_customers_email",
+	"idx_product_categories_name",
+	"idx_product_categories_code",
+	"idx_suppliers_code",
+	"idx_products_sku",
+	"idx_product_variants_sku",
+	"idx_orders_order_id",
+	"idx_orders_order_number",
+}
+
+// PrepareTenantColumns runs before AutoMigrate. It adds organization_id to
+// existing business tables and assigns their rows to the oldest organization so
+// the column can become NOT NULL, then drops the old global unique indexes.
+func PrepareTenantColumns(tx *gorm.DB) error {
+	var pending []string
+	for _, model := range TenantModels() {
+		if tx.Migrator().HasTable(model) && !tx.Migrator().HasColumn(model, "OrganizationID") {
+			statement := &gorm.Statement{DB: tx}
+			if err := statement.Parse(model); err != nil {
+				return err
+			}
+			pending = append(pending, statement.Schema.Table)
+		}
+	}
+
+	if len(pending) > 0 {
+		organizationID, err := defaultTenantOrganization(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, table := range pending {
+			if err := tx.Exec(fmt.Sprintf("ALTER TABLE %q ADD COLUMN organization_id bigint", table)).Error; err != nil {
+				return err
+			}
+			result := tx.Exec(fmt.Sprintf("UPDATE %q SET organization_id = ?", table), organizationID)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				log.Printf("Assigned %d existing %s rows to organization %d", result.RowsAffected, table, organizationID)
+			}
+		}
+	}
+
+	for _, index := range globalUniqueIndexes {
+		if err := tx.Exec(fmt.Sprintf("DROP INDEX IF EXISTS %q", index)).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PrepareOrganizationPermissions runs before AutoMigrate. Roles and direct grants
+// used to apply in every organization. The role moves from users.role_id to every
+// membership of the user, direct grants and elevation requests go to the
+// organization the user joined first. Those of users without any membership are
+// deleted, they can no longer apply anywhere.
+func PrepareOrganizationPermissions(tx *gorm.DB) error {
+	migrator := tx.Migrator()
+	legacyRoles := migrator.HasColumn("users", "role_id")
+
+	var pending []string
+	for _, model := range []interface{}{&PermissionElevationRequest{}, &UserPermission{}} {
+		if migrator.HasTable(model) && !migrator.HasColumn(model, "OrganizationID") {
+			statement := &gorm.Statement{DB: tx}
+			if err := statement.Parse(model); err != nil {
+				return err
+			}
+			pending = append(pending, statement.Schema.Table)
+		}
+	}
+
+	if !legacyRoles && len(pending) == 0 {
+		return nil
+	}
+
+	// Memberships decide where roles and grants end up, they have to exist first
+	if err := tx.AutoMigrate(&Organization{}, &OrganizationMember{}); err != nil {
+		return err
+	}
+	if err := BackfillOrganizationOwners(tx); err != nil {
+		return err
+	}
+
+	if legacyRoles {
+		result := tx.Exec(`
+			UPDATE organization_members m SET role_id = u.role_id
+			FROM users u
+			WHERE u.id = m.user_id AND u.role_id IS NOT NULL AND m.role_id IS NULL`)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("Moved the role of users to %d memberships", result.RowsAffected)
+		}
+		if err := tx.Exec("ALTER TABLE users DROP COLUMN role_id").Error; err != nil {
+			return err
+		}
+		err := tx.Exec("UPDATE roles SET user_count = (SELECT COUNT(*) FROM organization_members m WHERE m.role_id = roles.id)").Error
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, table := range pending {
+		if err := tx.Exec(fmt.Sprintf("ALTER TABLE %q ADD COLUMN organization_id bigint", table)).Error; err != nil {
+			return err
+		}
+		err := tx.Exec(fmt.Sprintf(`
+			UPDATE %q t SET organization_id = (
+				SELECT m.organization_id FROM organization_members m
+				WHERE m.user_id = t.user_id
+				ORDER BY m.created_at ASC, m.id ASC
+				LIMIT 1
+			)`, table)).Error
+		if err != nil {
+			return err
+		}
+		result := tx.Exec(fmt.Sprintf("DELETE FROM %q WHERE organization_id IS NULL", table))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("Deleted %d %s rows of users without an organization", result.RowsAffected, table)
+		}
+	}
+
+	return nil
+}
+
+// defaultTenantOrganization returns the oldest organization. A database without
+// one gets a default organization owned by the first superuser, or the first user.
+func defaultTenantOrganization(tx *gorm.DB) (uint, error) {
+	var organization Organization
+	if err := tx.Order("id").Limit(1).Find(&organization).Error; err != nil {
+		return 0, err
+	}
+	if organization.ID != 0 {
+		return organization.ID, nil
+	}
+
+	var owner User
+	if err := tx.Order("is_superuser DESC, id ASC").Limit(1).Find(&owner).Error; err != nil {
+		return 0, err
+	}
+	if owner.ID == 0 {
+		// Nothing references an organization without users, any rows are orphans
+		return 0, nil
+	}
+
+	organization = Organization{Name: "Default Organization", OwnerID: owner.ID}
+	if err := tx.Create(&organization).Error; err != nil {
+		return 0, err
+	}
+	log.Printf("Created %q for existing data", organization.Name)
+
+	return organization.ID, nil
+}
+
+// userReference is a column that points at a user. Optional references to a
+// purged user are cleared, required ones are handed to the user running the purge.
+type userReference struct {
+	model    interface{}
+	column   string
+	required bool
+}
+
+// userReferences lists every column with a foreign key to users, outside the
+// per user tables that are deleted with their user
+var userReferences = []userReference{
+	{&User{}, "manager_id", false},
+	{&User{}, "created_by", false},
+	{&User{}, "referred_by", false},
+	{&Organization{}, "owner_id", true},
+	{&OrganizationMember{}, "invited_by", false},
+	{&OrganizationInvitation{}, "invited_by", true},
+	{&APIKey{}, "created_by", true},
+	{&Role{}, "created_by", true},
+	{&RolePermission{}, "granted_by", true},
+	{&UserPermission{}, "granted_by", true},
+	{&PermissionElevationRequest{}, "reviewed_by", false},
+	{&Department{}, "manager_id", false},
+	{&Department{}, "created_by", true},
+	{&DepartmentBudget{}, "approved_by", false},
+	{&DepartmentPermission{}, "granted_by", true},
+	{&DepartmentHistory{}, "changed_by", true},
+	{&Customer{}, "created_by", true},
+	{&Order{}, "created_by", true},
+	{&Order{}, "assigned_to", false},
+	{&OrderHistory{}, "performed_by", true},
+	{&Supplier{}, "created_by", true},
+	{&Product{}, "created_by", true},
+	{&InventoryTransaction{}, "performed_by", true},
+	{&ProductPriceHistory{}, "changed_by", true},
+	{&ProductReview{}, "approved_by", false},
+}
+
+// PurgeOrganization hard deletes an organization: its business rows, soft deleted
+// ones included, settings, memberships, direct grants, elevation requests,
+// invitations, API keys and export records, and the users who belong to no other
+// organization. Superusers are never deleted.
+// Whatever else the purged users created or granted, in other organizations or in
+// global tables like roles, is handed to successorID, the user running the purge.
+// Run it in a transaction. It returns the number of deleted rows per table.
+func PurgeOrganization(tx *gorm.DB, organizationID, successorID uint) (map[string]int64, error) {
+	tx = tx.WithContext(tenant.WithOrganization(tx.Statement.Context, organizationID)).
+		Session(&gorm.Session{SkipHooks: true})
+	deleted := map[string]int64{}
+	record := func(result *gorm.DB) error {
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			deleted[result.Statement.Table] += result.RowsAffected
+		}
+		return nil
+	}
+
+	var userIDs []uint
+	err := tx.Model(&OrganizationMember{}).
+		Where("organization_id = ?", organizationID).
+		Where("user_id NOT IN (?)", tx.Model(&OrganizationMember{}).Select("user_id").Where("organization_id <> ?", organizationID)).
+		Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	var serviceAccountIDs []uint
+	if err := tx.Model(&APIKey{}).Where("organization_id = ?", organizationID).Pluck("service_account_id", &serviceAccountIDs).Error; err != nil {
+		return nil, err
+	}
+	var purgedUserIDs []uint
+	err = tx.Unscoped().Model(&User{}).
+		Where("id IN ? AND is_superuser = ?", append(userIDs, serviceAccountIDs...), false).
+		Pluck("id", &purgedUserIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Users of other organizations may still point at the departments about to go
+	err = tx.Model(&User{}).
+		Where("department_id IN (?)", tx.Model(&Department{}).Select("id").Where("organization_id = ?", organizationID)).
+		Updates(map[string]interface{}{"department_id": nil, "department_role_id": nil}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Children before parents
+	tenantModels := TenantModels()
+	for i := len(tenantModels) - 1; i >= 0; i-- {
+		if err := record(tx.Unscoped().Where("organization_id = ?", organizationID).Delete(tenantModels[i])); err != nil {
+			return nil, err
+		}
+	}
+
+	var roleIDs []uint
+	err = tx.Model(&OrganizationMember{}).Where("organization_id = ? AND role_id IS NOT NULL", organizationID).Distinct().Pluck("role_id", &roleIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	organizationModels := []interface{}{
+		&PermissionElevationRequest{},
+		&UserPermission{},
+		&OrganizationInvitation{},
+		&OrganizationMember{},
+		&APIKey{},
+		&OrganizationExport{},
+	}
+	for _, model := range organizationModels {
+		if err := record(tx.Unscoped().Where("organization_id = ?", organizationID).Delete(model)); err != nil {
+			return nil, err
+		}
+	}
+	for _, roleID := range roleIDs {
+		role := Role{ID: roleID}
+		if err := role.UpdateUserCount(tx); err != nil {
+			return nil, err
+		}
+	}
+	if err := record(tx.Unscoped().Where("id = ?", organizationID).Delete(&Organization{})); err != nil {
+		return nil, err
+	}
+
+	if len(purgedUserIDs) > 0 {
+		// The references left are rows of other organizations
+		global := tx.WithContext(tenant.WithoutScope(tx.Statement.Context))
+		for _, reference := range userReferences {
+			var successor interface{}
+			if reference.required {
+				successor = successorID
+			}
+			err = global.Unscoped().Model(reference.model).Where(reference.column+" IN ?", purgedUserIDs).Update(reference.column, successor).Error
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := record(global.Unscoped().Where("customer_id IN ?", purgedUserIDs).Delete(&ProductReview{})); err != nil {
+			return nil, err
+		}
+
+		userModels := []interface{}{
+			&TokenBlacklist{},
+			&RefreshToken{},
+			&PasswordResetToken{},
+			&UserSession{},
+			&UserIdentity{},
+			&UserPermission{},
+			&UserProfile{},
+			&PermissionElevationRequest{},
+			&UserHistory{},
+		}
+		for _, model := range userModels {
+			if err := record(tx.Unscoped().Where("user_id IN ?", purgedUserIDs).Delete(model)); err != nil {
+				return nil, err
+			}
+		}
+		if err := record(tx.Unscoped().Where("id IN ?", purgedUserIDs).Delete(&User{})); err != nil {
+			return nil, err
+		}
+	}
+
+	return deleted, nil
+}
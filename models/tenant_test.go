@@ -0,0 +1,145 @@
+package models_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/farhapartex/ainventory/config"
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/tenant"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// testDB connects to the database named by TEST_DATABASE_URL and migrates it,
+// tests that need one are skipped without it
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("connecting to the test database: %v", err)
+	}
+	if err := tenant.Register(db, models.TenantModels()...); err != nil {
+		t.Fatalf("registering organization scoping: %v", err)
+	}
+	config.DB = db
+	config.MigrateDB()
+
+	return db
+}
+
+func TestPurgeOrganizationHandsOverReferences(t *testing.T) {
+	db := testDB(t)
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	suffix := time.Now().UnixNano()
+	create := func(value interface{}) {
+		t.Helper()
+		if err := tx.Omit(clause.Associations).Create(value).Error; err != nil {
+			t.Fatalf("creating %T: %v", value, err)
+		}
+	}
+	newUser := func(name string, superuser bool) *models.User {
+		user := &models.User{
+			FirstName:   name,
+			LastName:    "Test",
+			Email:       fmt.Sprintf("%s-%d@example.com", name, suffix),
+			Password:    "password123",
+			IsSuperuser: superuser,
+		}
+		create(user)
+		return user
+	}
+
+	superuser := newUser("superuser", true)
+	admin := newUser("admin", false)
+	outsider := newUser("outsider", false)
+
+	purged := &models.Organization{Name: fmt.Sprintf("Purged %d", suffix), OwnerID: admin.ID}
+	create(purged)
+	create(&models.OrganizationMember{OrganizationID: purged.ID, UserID: admin.ID, Role: models.OrganizationRoleOwner})
+
+	// The admin also left traces outside the purged organization
+	kept := &models.Organization{Name: fmt.Sprintf("Kept %d", suffix), OwnerID: admin.ID}
+	create(kept)
+	create(&models.OrganizationMember{OrganizationID: kept.ID, UserID: outsider.ID, Role: models.OrganizationRoleOwner, InvitedBy: &admin.ID})
+	create(&models.OrganizationInvitation{
+		OrganizationID: kept.ID,
+		Email:          fmt.Sprintf("invitee-%d@example.com", suffix),
+		Role:           models.OrganizationRoleMember,
+		TokenHash:      fmt.Sprintf("%064d", suffix),
+		ExpiresAt:      time.Now().Add(time.Hour),
+		InvitedBy:      admin.ID,
+	})
+	if err := tx.Model(outsider).Update("created_by", admin.ID).Error; err != nil {
+		t.Fatalf("setting the creator of the outsider: %v", err)
+	}
+
+	permission := &models.Permission{
+		Name:        fmt.Sprintf("purge_test_%d", suffix),
+		DisplayName: "Purge test",
+		Module:      "purge_test",
+		Action:      "read",
+	}
+	create(permission)
+	role := &models.Role{
+		Name:        fmt.Sprintf("purge_test_%d", suffix),
+		DisplayName: "Purge test",
+		Level:       1,
+		CreatedBy:   admin.ID,
+	}
+	create(role)
+	create(&models.RolePermission{RoleID: role.ID, PermissionID: permission.ID, GrantedBy: admin.ID})
+	create(&models.UserPermission{OrganizationID: kept.ID, UserID: outsider.ID, PermissionID: permission.ID, IsGranted: true, GrantedBy: admin.ID})
+	create(&models.UserPermission{OrganizationID: purged.ID, UserID: outsider.ID, PermissionID: permission.ID, IsGranted: true, GrantedBy: admin.ID})
+
+	if _, err := models.PurgeOrganization(tx, purged.ID, superuser.ID); err != nil {
+		t.Fatalf("purging the organization: %v", err)
+	}
+
+	var remaining int64
+	tx.Unscoped().Model(&models.User{}).Where("id = ?", admin.ID).Count(&remaining)
+	if remaining != 0 {
+		t.Errorf("the admin of the purged organization was not deleted")
+	}
+
+	var purgedGrants int64
+	tx.Model(&models.UserPermission{}).Where("organization_id = ?", purged.ID).Count(&purgedGrants)
+	if purgedGrants != 0 {
+		t.Errorf("%d direct grants of the purged organization were kept", purgedGrants)
+	}
+
+	references := []struct {
+		name  string
+		model interface{}
+		where string
+		args  []interface{}
+	}{
+		{"role creator", &models.Role{}, "id = ? AND created_by = ?", []interface{}{role.ID, superuser.ID}},
+		{"role permission grantor", &models.RolePermission{}, "role_id = ? AND granted_by = ?", []interface{}{role.ID, superuser.ID}},
+		{"user permission grantor", &models.UserPermission{}, "user_id = ? AND granted_by = ?", []interface{}{outsider.ID, superuser.ID}},
+		{"organization owner", &models.Organization{}, "id = ? AND owner_id = ?", []interface{}{kept.ID, superuser.ID}},
+		{"invitation sender", &models.OrganizationInvitation{}, "organization_id = ? AND invited_by = ?", []interface{}{kept.ID, superuser.ID}},
+		{"membership inviter", &models.OrganizationMember{}, "organization_id = ? AND invited_by IS NULL", []interface{}{kept.ID}},
+		{"user creator", &models.User{}, "id = ? AND created_by IS NULL", []interface{}{outsider.ID}},
+	}
+	for _, reference := range references {
+		var count int64
+		if err := tx.Model(reference.model).Where(reference.where, reference.args...).Count(&count).Error; err != nil {
+			t.Fatalf("%s: %v", reference.name, err)
+		}
+		if count != 1 {
+			t.Errorf("%s was not handed over", reference.name)
+		}
+	}
+}
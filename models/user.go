@@ -1,9 +1,12 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/farhapartex/ainventory/tenant"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -27,7 +30,6 @@ type User struct {
 	DepartmentID     *uint      `json:"department_id" gorm:"index"`
 	DepartmentRoleID *uint      `json:"department_role_id" gorm:"index"`
 	ManagerID        *uint      `json:"manager_id" gorm:"index"`
-	RoleID           *uint      `json:"role_id" gorm:"index"`
 	JobTitle         string     `json:"job_title" gorm:"size:150"`
 	WorkLocation     string     `json:"work_location" gorm:"size:150;default:'Main Office'"`
 	ContractType     string     `json:"contract_type" gorm:"size:50;default:'Full-time';check:contract_type IN ('Full-time', 'Part-time', 'Contract', 'Intern', 'Consultant')"`
@@ -39,13 +41,17 @@ type User struct {
 	Currency         string     `json:"currency" gorm:"size:3;default:'USD'"`
 	PayGrade         string     `json:"pay_grade" gorm:"size:20"`
 	IsSuperuser      bool       `gorm:"default:false" json:"is_superuser"`
+	IsServiceAccount bool       `gorm:"default:false" json:"is_service_account"` // Backs an APIKey, cannot sign in
 	Status           string     `gorm:"size:20;default:active;" json:"status"`
 	EmailVerified    bool       `gorm:"default:false" json:"email_verified"`
 	VerifiedAt       *time.Time `json:"verified_at"`
 
+	VerificationSentAt *time.Time `json:"verification_sent_at"`
+
 	TwoFactorEnabled       bool       `json:"two_factor_enabled" gorm:"default:false"`
-	TwoFactorSecret        string     `json:"two_factor_secret,omitempty" gorm:"size:255"`
-	BackupCodes            string     `json:"backup_codes,omitempty" gorm:"type:text"`
+	TwoFactorSecret        string     `json:"-" gorm:"size:255"`
+	TwoFactorLastCounter   int64      `json:"-" gorm:"default:0"` // Last accepted TOTP time step, prevents replay
+	BackupCodes            string     `json:"-" gorm:"type:text"` // JSON array of bcrypt hashed one time codes
 	AccountLocked          bool       `json:"account_locked" gorm:"default:false"`
 	LockedAt               *time.Time `json:"locked_at"`
 	FailedLoginAttempts    int        `json:"failed_login_attempts" gorm:"default:0"`
@@ -76,22 +82,21 @@ type User struct {
 	EmergencyContactPhone    string `json:"emergency_contact_phone" gorm:"size:20"`
 	EmergencyContactRelation string `json:"emergency_contact_relation" gorm:"size:50"`
 
-	CreatedBy       *uint            `json:"created_by" gorm:"index"`
-	JoinedAt        time.Time        `gorm:"default:CURRENT_TIMESTAMP" json:"joined_at"`
-	CreatedAt       time.Time        `json:"created_at"`
-	UpdatedAt       time.Time        `json:"updated_at"`
-	DeletedAt       gorm.DeletedAt   `json:"deleted_at,omitempty" gorm:"index"`
-	Role            *Role            `json:"role,omitempty" gorm:"foreignKey:RoleID"`
-	Department      *Department      `json:"department,omitempty" gorm:"foreignKey:DepartmentID"`
-	DepartmentRole  *DepartmentRole  `json:"department_role,omitempty" gorm:"foreignKey:DepartmentRoleID"`
-	Manager         *User            `json:"manager,omitempty" gorm:"foreignKey:ManagerID"`
-	DirectReports   []User           `json:"direct_reports,omitempty" gorm:"foreignKey:ManagerID"`
-	Creator         *User            `json:"creator,omitempty" gorm:"foreignKey:CreatedBy"`
-	ReferredByUser  *User            `json:"referred_by_user,omitempty" gorm:"foreignKey:ReferredBy"`
-	UserHistory     []UserHistory    `json:"user_history,omitempty" gorm:"foreignKey:UserID"`
-	UserPermissions []UserPermission `json:"user_permissions,omitempty" gorm:"foreignKey:UserID"`
-	TokenBlacklist  []TokenBlacklist `json:"token_blacklist,omitempty" gorm:"foreignKey:UserID"`
-	Organizations   []Organization   `json:"organizations" gorm:"foreignKey:OwnerID"`
+	CreatedBy       *uint                `json:"created_by" gorm:"index"`
+	JoinedAt        time.Time            `gorm:"default:CURRENT_TIMESTAMP" json:"joined_at"`
+	CreatedAt       time.Time            `json:"created_at"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt       `json:"deleted_at,omitempty" gorm:"index"`
+	Department      *Department          `json:"department,omitempty" gorm:"foreignKey:DepartmentID"`
+	DepartmentRole  *DepartmentRole      `json:"department_role,omitempty" gorm:"foreignKey:DepartmentRoleID"`
+	Manager         *User                `json:"manager,omitempty" gorm:"foreignKey:ManagerID"`
+	DirectReports   []User               `json:"direct_reports,omitempty" gorm:"foreignKey:ManagerID"`
+	Creator         *User                `json:"creator,omitempty" gorm:"foreignKey:CreatedBy"`
+	ReferredByUser  *User                `json:"referred_by_user,omitempty" gorm:"foreignKey:ReferredBy"`
+	UserHistory     []UserHistory        `json:"user_history,omitempty" gorm:"foreignKey:UserID"`
+	UserPermissions []UserPermission     `json:"user_permissions,omitempty" gorm:"foreignKey:UserID"`
+	TokenBlacklist  []TokenBlacklist     `json:"token_blacklist,omitempty" gorm:"foreignKey:UserID"`
+	Memberships     []OrganizationMember `json:"memberships" gorm:"foreignKey:UserID"`
 }
 
 type TokenBlacklist struct {
@@ -105,32 +110,85 @@ type TokenBlacklist struct {
 	User      User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
 }
 
+// RefreshToken tracks issued refresh tokens so they can be rotated and reuse detected
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	FamilyID  string     `json:"family_id" gorm:"not null;index;size:64"`        // Shared by all rotations of one login
+	TokenHash string     `json:"token_hash" gorm:"uniqueIndex;not null;size:64"` // SHA256 hash of token
+	ExpiresAt time.Time  `json:"expires_at" gorm:"index"`
+	UsedAt    *time.Time `json:"used_at"`    // Set once the token is exchanged for a new pair
+	RevokedAt *time.Time `json:"revoked_at"` // Set when the whole family is revoked
+	CreatedAt time.Time  `json:"created_at"`
+	User      User       `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// PasswordResetToken is a single use token emailed by the forgot password flow
+type PasswordResetToken struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	TokenHash   string     `json:"token_hash" gorm:"uniqueIndex;not null;size:64"` // SHA256 hash of token
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"index"`
+	UsedAt      *time.Time `json:"used_at"`
+	RequestedIP string     `json:"requested_ip" gorm:"size:45"`
+	CreatedAt   time.Time  `json:"created_at"`
+	User        User       `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// UserSession is one signed in device, identified by the refresh token family issued at sign in
+type UserSession struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	FamilyID   string     `json:"-" gorm:"uniqueIndex;not null;size:64"`
+	UserAgent  string     `json:"user_agent" gorm:"size:500"`
+	IPAddress  string     `json:"ip_address" gorm:"size:45"` // IP address at sign in
+	LastSeenIP string     `json:"last_seen_ip" gorm:"size:45"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"index"` // Moves forward every time the refresh token rotates
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	User       User       `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// UserHistory is the audit trail. Rows written for an API request also carry the
+// route, the entity it acted on and its outcome, account events leave them empty.
 type UserHistory struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	UserID    uint      `json:"user_id" gorm:"not null;index"`
-	Action    string    `json:"action" gorm:"not null;size:50"`
-	Details   string    `json:"details" gorm:"type:text"`
-	IPAddress string    `json:"ip_address" gorm:"size:45"`
-	UserAgent string    `json:"user_agent" gorm:"size:500"`
-	CreatedAt time.Time `json:"created_at"`
-	User      User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	UserID         *uint     `json:"user_id" gorm:"index"`         // Actor of a request, subject of an account event, nil for unknown callers
+	OrganizationID *uint     `json:"organization_id" gorm:"index"` // Organization the request or event acted in
+	Action         string    `json:"action" gorm:"not null;size:100;index"`
+	Details        string    `json:"details" gorm:"type:text"`
+	IPAddress      string    `json:"ip_address" gorm:"size:45"`
+	UserAgent      string    `json:"user_agent" gorm:"size:500"`
+	Method         string    `json:"method" gorm:"size:10"`
+	Route          string    `json:"route" gorm:"size:255"` // Route template, like /api/v1/roles/:id
+	EntityType     string    `json:"entity_type" gorm:"size:50;index"`
+	EntityID       string    `json:"entity_id" gorm:"size:64;index"`
+	StatusCode     int       `json:"status_code"`
+	Outcome        string    `json:"outcome" gorm:"size:20;index"` // success or failure
+	RequestID      string    `json:"request_id" gorm:"size:64;index"`
+	CreatedAt      time.Time `json:"created_at" gorm:"index"`
+	User           User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
 }
 
+// UserPermission grants or denies a permission to a user directly, within one organization
 type UserPermission struct {
-	ID            uint       `json:"id" gorm:"primaryKey"`
-	UserID        uint       `json:"user_id" gorm:"not null;index"`
-	PermissionID  uint       `json:"permission_id" gorm:"not null;index"`
-	IsGranted     bool       `json:"is_granted" gorm:"default:true"`
-	GrantedBy     uint       `json:"granted_by" gorm:"not null;index"`
-	GrantedAt     time.Time  `json:"granted_at" gorm:"autoCreateTime"`
-	ExpiresAt     *time.Time `json:"expires_at"`
-	Reason        string     `json:"reason" gorm:"size:300"`
-	User          User       `json:"user,omitempty" gorm:"foreignKey:UserID"`
-	Permission    Permission `json:"permission,omitempty" gorm:"foreignKey:PermissionID"`
-	GrantedByUser User       `json:"granted_by_user,omitempty" gorm:"foreignKey:GrantedBy"`
-
-	_ struct{} `gorm:"uniqueIndex:idx_user_permission,priority:1" sql:"user_id"`
-	_ struct{} `gorm:"uniqueIndex:idx_user_permission,priority:2" sql:"permission_id"`
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	OrganizationID uint       `json:"organization_id" gorm:"not null;index"`
+	UserID         uint       `json:"user_id" gorm:"not null;index"`
+	PermissionID   uint       `json:"permission_id" gorm:"not null;index"`
+	IsGranted      bool       `json:"is_granted" gorm:"default:true"`
+	GrantedBy      uint       `json:"granted_by" gorm:"not null;index"`
+	GrantedAt      time.Time  `json:"granted_at" gorm:"autoCreateTime"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	Reason         string     `json:"reason" gorm:"size:300"`
+	User           User       `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Permission     Permission `json:"permission,omitempty" gorm:"foreignKey:PermissionID"`
+	GrantedByUser  User       `json:"granted_by_user,omitempty" gorm:"foreignKey:GrantedBy"`
+
+	_ struct{} `gorm:"uniqueIndex:idx_user_permission,priority:1" sql:"organization_id"`
+	_ struct{} `gorm:"uniqueIndex:idx_user_permission,priority:2" sql:"user_id"`
+	_ struct{} `gorm:"uniqueIndex:idx_user_permission,priority:3" sql:"permission_id"`
 }
 
 type UserProfile struct {
@@ -159,6 +217,17 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 		u.EmployeeID = generateEmployeeID(tx)
 	}
 
+	// Users created within an organization, like by its administrators, inherit its settings
+	if u.Currency == "" || u.Country == "" {
+		settings := SettingsFor(tx)
+		if u.Currency == "" {
+			u.Currency = settings.BaseCurrency
+		}
+		if u.Country == "" {
+			u.Country = settings.Country
+		}
+	}
+
 	if len(u.Password) < 60 {
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
 		if err != nil {
@@ -190,7 +259,8 @@ func (u *User) BeforeUpdate(tx *gorm.DB) error {
 }
 
 func (u *User) BeforeDelete(tx *gorm.DB) error {
-	return tx.Model(&Department{}).Where("id = ?", u.DepartmentID).
+	// Users are not tied to one organization, their department is found by ID alone
+	return tx.WithContext(tenant.WithoutScope(tx.Statement.Context)).Model(&Department{}).Where("id = ?", u.DepartmentID).
 		Update("employee_count", gorm.Expr("employee_count - 1")).Error
 }
 
@@ -209,6 +279,34 @@ func (u *User) ShouldChangePassword() bool {
 	return u.MustChangePassword || u.IsPasswordExpired()
 }
 
+// PasswordMaxAgeDays returns the strictest password max age of the user's organizations, 0 if none applies
+func (u *User) PasswordMaxAgeDays(tx *gorm.DB) int {
+	var maxAge int
+	tx.Model(&Organization{}).
+		Joins("JOIN organization_members ON organization_members.organization_id = organizations.id").
+		Where("organization_members.user_id = ? AND organizations.password_max_age_days > 0", u.ID).
+		Select("COALESCE(MIN(organizations.password_max_age_days), 0)").
+		Scan(&maxAge)
+	return maxAge
+}
+
+// ApplyPasswordExpiry sets PasswordExpiresAt from the organization password policy.
+// The caller is responsible for saving the user.
+func (u *User) ApplyPasswordExpiry(tx *gorm.DB) {
+	maxAge := u.PasswordMaxAgeDays(tx)
+	if maxAge == 0 {
+		u.PasswordExpiresAt = nil
+		return
+	}
+
+	changedAt := u.CreatedAt
+	if u.LastPasswordChangedAt != nil {
+		changedAt = *u.LastPasswordChangedAt
+	}
+	expiresAt := changedAt.AddDate(0, 0, maxAge)
+	u.PasswordExpiresAt = &expiresAt
+}
+
 func (u *User) ResetFailedLoginAttempts(tx *gorm.DB) error {
 	u.FailedLoginAttempts = 0
 	u.LastFailedLoginAt = nil
@@ -232,19 +330,65 @@ func (u *User) GetFullName() string {
 	return u.FirstName + " " + u.LastName
 }
 
+// HasPermission reports whether the user holds the permission in the organization
+// active on tx through ownership, their role, their department or one of its
+// ancestors, or a direct grant. An explicit deny from the department chain or a
+// direct grant always wins.
 func (u *User) HasPermission(tx *gorm.DB, permissionName string) bool {
-	if u.Role.HasPermission(tx, permissionName) {
-		return true
+	grants, err := u.PermissionGrants(tx, permissionName)
+	if err != nil {
+		return false
 	}
-	var count int64
-	tx.Table("user_permissions").
-		Joins("JOIN permissions ON permissions.id = user_permissions.permission_id").
-		Where("user_permissions.user_id = ? AND permissions.name = ? AND user_permissions.is_granted = ? AND permissions.is_active = ?",
-			u.ID, permissionName, true, true).
-		Where("user_permissions.expires_at IS NULL OR user_permissions.expires_at > ?", time.Now()).
-		Count(&count)
 
-	return count > 0
+	for _, permission := range resolvePermissionGrants(grants) {
+		if permission.Allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RoleLevel is the Role.Level of the user's role in the organization active on tx.
+// Superusers rank above every role and owners of the organization with the highest
+// one, users without an active role rank lowest.
+func (u *User) RoleLevel(tx *gorm.DB) int {
+	if u.IsSuperuser {
+		return MaxRoleLevel + 1
+	}
+
+	membership := u.Membership(tx)
+	if membership == nil {
+		return 0
+	}
+	if membership.Role == OrganizationRoleOwner {
+		return MaxRoleLevel
+	}
+	if membership.RoleID == nil {
+		return 0
+	}
+
+	var role Role
+	if tx.Where("id = ? AND is_active = ?", *membership.RoleID, true).First(&role).Error != nil {
+		return 0
+	}
+	return role.Level
+}
+
+// DirectPermissionIDs returns the ids of permissions granted to the user directly
+// in the organization active on tx, not through their role
+func (u *User) DirectPermissionIDs(tx *gorm.DB) []uint {
+	organizationID, ok := tenant.OrganizationID(tx.Statement.Context)
+	if !ok {
+		return nil
+	}
+
+	var ids []uint
+	tx.Model(&UserPermission{}).
+		Where("organization_id = ? AND user_id = ? AND is_granted = ?", organizationID, u.ID, true).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Pluck("permission_id", &ids)
+	return ids
 }
 
 func (u *User) IncrementTokenVersion(tx *gorm.DB) error {
@@ -288,6 +432,103 @@ func (u *User) IsTokenBlacklisted(tx *gorm.DB, tokenJTI string) bool {
 	return count > 0
 }
 
+// PurgeExpiredTokens removes token and session rows that can no longer be used
+func PurgeExpiredTokens(tx *gorm.DB) (int64, error) {
+	now := time.Now()
+
+	result := tx.Where("expires_at < ?", now).Delete(&TokenBlacklist{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	purged := result.RowsAffected
+
+	result = tx.Where("expires_at < ?", now).Delete(&RefreshToken{})
+	if result.Error != nil {
+		return purged, result.Error
+	}
+	purged += result.RowsAffected
+
+	result = tx.Where("expires_at < ?", now).Delete(&PasswordResetToken{})
+	if result.Error != nil {
+		return purged, result.Error
+	}
+	purged += result.RowsAffected
+
+	result = tx.Where("expires_at < ?", now).Delete(&UserSession{})
+	if result.Error != nil {
+		return purged, result.Error
+	}
+	purged += result.RowsAffected
+
+	result = tx.Where("expires_at < ?", now).Delete(&OIDCLoginState{})
+	if result.Error != nil {
+		return purged, result.Error
+	}
+
+	return purged + result.RowsAffected, nil
+}
+
+// IsUsable reports whether the refresh token can still be exchanged
+func (rt *RefreshToken) IsUsable() bool {
+	return rt.UsedAt == nil && rt.RevokedAt == nil && time.Now().Before(rt.ExpiresAt)
+}
+
+// RevokeRefreshTokenFamily revokes every refresh token issued for the same login
+func RevokeRefreshTokenFamily(tx *gorm.DB, familyID string) error {
+	return tx.Model(&RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeSession ends a session, its access tokens stop working and its refresh tokens are revoked
+func RevokeSession(tx *gorm.DB, familyID string) error {
+	err := tx.Model(&UserSession{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return err
+	}
+
+	return RevokeRefreshTokenFamily(tx, familyID)
+}
+
+// TouchSession returns the session of a token family and records activity on it.
+// last_seen_at is written at most once a minute to keep authenticated requests cheap.
+func TouchSession(tx *gorm.DB, familyID, ipAddress string) (*UserSession, error) {
+	var session UserSession
+	if err := tx.Where("family_id = ?", familyID).First(&session).Error; err != nil {
+		return nil, err
+	}
+
+	if session.RevokedAt == nil && time.Since(session.LastSeenAt) > time.Minute {
+		tx.Model(&session).UpdateColumns(map[string]interface{}{
+			"last_seen_at": time.Now(),
+			"last_seen_ip": ipAddress,
+		})
+	}
+
+	return &session, nil
+}
+
+// AddHistory writes an entry to the user's history, details are stored as JSON
+func (u *User) AddHistory(tx *gorm.DB, action string, details map[string]interface{}, ipAddress string) error {
+	encoded, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+
+	history := UserHistory{
+		UserID:    &u.ID,
+		Action:    action,
+		Details:   string(encoded),
+		IPAddress: ipAddress,
+	}
+	if organizationID, ok := tenant.OrganizationID(tx.Statement.Context); ok {
+		history.OrganizationID = &organizationID
+	}
+	return tx.Create(&history).Error
+}
+
 // LogoutAllSessions invalidates all user tokens by incrementing version
 func (u *User) LogoutAllSessions(tx *gorm.DB, reason string) error {
 	// Increment token version to invalidate all tokens
@@ -295,11 +536,27 @@ func (u *User) LogoutAllSessions(tx *gorm.DB, reason string) error {
 		return err
 	}
 
+	// Refresh tokens carry the old version too, revoke them so they disappear from storage
+	err := tx.Model(&RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", u.ID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return err
+	}
+
+	err = tx.Model(&UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", u.ID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return err
+	}
+
 	// Log the action
+	details, _ := json.Marshal(map[string]string{"reason": reason})
 	history := UserHistory{
-		UserID:  u.ID,
+		UserID:  &u.ID,
 		Action:  "LOGOUT_ALL",
-		Details: fmt.Sprintf(`{"reason": "%s"}`, reason),
+		Details: string(details),
 	}
 	return tx.Create(&history).Error
 }
@@ -321,13 +578,18 @@ func (u *User) SetPassword(password string) error {
 	return nil
 }
 
-func (u *User) IncrementFailedLoginAttempts(tx *gorm.DB) error {
-	u.FailedLoginAttempts++
+// IncrementFailedLoginAttempts counts a failed sign in and locks the account once
+// maxAttempts failures happened without a gap longer than window between them
+func (u *User) IncrementFailedLoginAttempts(tx *gorm.DB, maxAttempts int, window time.Duration) error {
 	now := time.Now()
+	if u.LastFailedLoginAt != nil && now.Sub(*u.LastFailedLoginAt) > window {
+		u.FailedLoginAttempts = 0
+	}
+
+	u.FailedLoginAttempts++
 	u.LastFailedLoginAt = &now
 
-	// Lock account after 5 failed attempts
-	if u.FailedLoginAttempts >= 5 {
+	if u.FailedLoginAttempts >= maxAttempts {
 		u.AccountLocked = true
 		u.LockedAt = &now
 	}
@@ -335,6 +597,14 @@ func (u *User) IncrementFailedLoginAttempts(tx *gorm.DB) error {
 	return tx.Save(u).Error
 }
 
+// IsLockExpired reports whether a locked account has served its lockout duration
+func (u *User) IsLockExpired(lockout time.Duration) bool {
+	if !u.AccountLocked || u.LockedAt == nil {
+		return false
+	}
+	return time.Now().After(u.LockedAt.Add(lockout))
+}
+
 func (u *User) UnlockAccount(tx *gorm.DB) error {
 	u.AccountLocked = false
 	u.LockedAt = nil
@@ -343,12 +613,69 @@ func (u *User) UnlockAccount(tx *gorm.DB) error {
 	return tx.Save(u).Error
 }
 
+// SetBackupCodes replaces the user's backup codes, only hashes are stored
+func (u *User) SetBackupCodes(codes []string) error {
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		hashes[i] = string(hashed)
+	}
+
+	encoded, err := json.Marshal(hashes)
+	if err != nil {
+		return err
+	}
+	u.BackupCodes = string(encoded)
+	return nil
+}
+
+// UseBackupCode consumes a matching backup code, each code works only once
+func (u *User) UseBackupCode(tx *gorm.DB, code string) bool {
+	var hashes []string
+	if u.BackupCodes == "" || json.Unmarshal([]byte(u.BackupCodes), &hashes) != nil {
+		return false
+	}
+
+	code = strings.ToLower(strings.TrimSpace(code))
+	for i, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(code)) == nil {
+			remaining := append(hashes[:i:i], hashes[i+1:]...)
+			encoded, _ := json.Marshal(remaining)
+			u.BackupCodes = string(encoded)
+			return tx.Model(u).Update("backup_codes", u.BackupCodes).Error == nil
+		}
+	}
+
+	return false
+}
+
+// RemainingBackupCodes returns how many unused backup codes the user has
+func (u *User) RemainingBackupCodes() int {
+	var hashes []string
+	if u.BackupCodes == "" || json.Unmarshal([]byte(u.BackupCodes), &hashes) != nil {
+		return 0
+	}
+	return len(hashes)
+}
+
+// DisableTwoFactor clears every two factor setting of the user
+func (u *User) DisableTwoFactor(tx *gorm.DB) error {
+	u.TwoFactorEnabled = false
+	u.TwoFactorSecret = ""
+	u.TwoFactorLastCounter = 0
+	u.BackupCodes = ""
+	return tx.Save(u).Error
+}
+
 func (u *User) IsActive() bool {
 	return u.Status == "active" && !u.AccountLocked
 }
 
 func (u *User) CanLogin() bool {
-	return u.IsActive() && u.EmailVerified
+	return u.IsActive() && u.EmailVerified && !u.IsServiceAccount
 }
 
 func generateUserID(tx *gorm.DB) string {
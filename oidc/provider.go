@@ -0,0 +1,299 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Provider talks to one OpenID Connect identity provider using the authorization
+// code flow with PKCE
+type Provider struct {
+	IssuerURL     string
+	ClientID      string
+	ClientSecret  string // Empty for public clients, PKCE alone protects the code
+	RedirectURL   string
+	Scopes        []string
+	GroupsClaim   string
+	RoleMapping   map[string]string // IdP group to Role.Name
+	AutoProvision bool              // Create unknown users on their first sign in, off unless OIDC_AUTO_PROVISION=true
+	HTTPClient    *http.Client
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	keys      *keyCache
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// TokenResponse is the answer of the token endpoint to a code exchange
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+}
+
+// Claims are the ID token claims the application relies on
+type Claims struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	GivenName     string
+	FamilyName    string
+	Groups        []string
+}
+
+// NewFromEnv builds the provider configured by the OIDC_* variables, it returns
+// nil when OIDC_ISSUER_URL is not set and single sign on is disabled
+func NewFromEnv() *Provider {
+	issuer := strings.TrimRight(os.Getenv("OIDC_ISSUER_URL"), "/")
+	if issuer == "" {
+		return nil
+	}
+
+	scopes := strings.Fields(os.Getenv("OIDC_SCOPES"))
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+
+	groupsClaim := os.Getenv("OIDC_GROUPS_CLAIM")
+	if groupsClaim == "" {
+		groupsClaim = "groups"
+	}
+
+	return &Provider{
+		IssuerURL:     issuer,
+		ClientID:      os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret:  os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:   os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:        scopes,
+		GroupsClaim:   groupsClaim,
+		RoleMapping:   parseRoleMapping(os.Getenv("OIDC_ROLE_MAPPING")),
+		AutoProvision: os.Getenv("OIDC_AUTO_PROVISION") == "true",
+		HTTPClient:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// AuthCodeURL returns the identity provider URL the browser is sent to
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientID},
+		"redirect_uri":          {p.RedirectURL},
+		"scope":                 {strings.Join(p.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return doc.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange trades an authorization code and its PKCE verifier for tokens
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (*TokenResponse, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.RedirectURL},
+		"client_id":     {p.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+
+	var token TokenResponse
+	if err := p.do(req, &token); err != nil {
+		return nil, fmt.Errorf("token exchange: %w", err)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("token exchange: no id_token in response")
+	}
+
+	return &token, nil
+}
+
+// VerifyIDToken checks the signature of an ID token against the provider JWKS
+// as well as its issuer, audience, expiry and nonce
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*Claims, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384", "EdDSA"}),
+		jwt.WithIssuer(doc.Issuer),
+		jwt.WithAudience(p.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+
+	claims := jwt.MapClaims{}
+	_, err = parser.ParseWithClaims(rawIDToken, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.keys.get(ctx, p, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+
+	if claimString(claims, "nonce") != nonce {
+		return nil, errors.New("invalid id token: nonce mismatch")
+	}
+
+	// A token issued to several audiences must name us as the authorized party
+	if aud, _ := claims.GetAudience(); len(aud) > 1 && claimString(claims, "azp") != p.ClientID {
+		return nil, errors.New("invalid id token: authorized party mismatch")
+	}
+
+	result := &Claims{
+		Issuer:     doc.Issuer,
+		Subject:    claimString(claims, "sub"),
+		Email:      strings.ToLower(claimString(claims, "email")),
+		GivenName:  claimString(claims, "given_name"),
+		FamilyName: claimString(claims, "family_name"),
+		Groups:     claimStrings(claims, p.GroupsClaim),
+	}
+	if verified, ok := claims["email_verified"].(bool); ok {
+		result.EmailVerified = verified
+	}
+	if result.Subject == "" {
+		return nil, errors.New("invalid id token: missing subject")
+	}
+
+	return result, nil
+}
+
+// MappedRoles returns the role names the given IdP groups map to
+func (p *Provider) MappedRoles(groups []string) []string {
+	roles := []string{}
+	for _, group := range groups {
+		if role, ok := p.RoleMapping[group]; ok {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+func (p *Provider) discover(ctx context.Context) (*discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.IssuerURL+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc discoveryDocument
+	if err := p.do(req, &doc); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+	if strings.TrimRight(doc.Issuer, "/") != p.IssuerURL {
+		return nil, fmt.Errorf("discovery: issuer %q does not match %q", doc.Issuer, p.IssuerURL)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, errors.New("discovery: incomplete provider metadata")
+	}
+
+	p.discovery = &doc
+	p.keys = &keyCache{uri: doc.JWKSURI}
+	return p.discovery, nil
+}
+
+func (p *Provider) do(req *http.Request, out interface{}) error {
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d: %s", req.URL.Redacted(), resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return json.Unmarshal(body, out)
+}
+
+// parseRoleMapping reads "group=role,group=role"
+func parseRoleMapping(value string) map[string]string {
+	mapping := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		group, role, ok := strings.Cut(pair, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if ok && group != "" && role != "" {
+			mapping[group] = role
+		}
+	}
+	return mapping
+}
+
+func claimString(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// claimStrings reads a claim holding either a list of strings or a single string
+func claimStrings(claims jwt.MapClaims, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := []string{}
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
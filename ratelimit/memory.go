@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval controls how often idle buckets are dropped from memory
+const sweepInterval = 10 * time.Minute
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	window    time.Duration
+}
+
+// MemoryLimiter keeps buckets in process memory. Every instance counts on its
+// own, so it only fits single instance deployments.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets:   map[string]*bucket{},
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *MemoryLimiter) Take(ctx context.Context, policy Policy, key string) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(policy.Limit), updatedAt: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(policy.Limit), b.tokens+now.Sub(b.updatedAt).Seconds()*policy.rate())
+	b.updatedAt = now
+	b.window = policy.Window
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	return result(policy, b.tokens, allowed), nil
+}
+
+// sweep drops buckets that had time to refill completely, they are equivalent
+// to a new bucket
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.updatedAt) > b.window {
+			delete(l.buckets, key)
+		}
+	}
+}
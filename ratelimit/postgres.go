@@ -0,0 +1,45 @@
+package ratelimit
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// PostgresLimiter keeps buckets in the rate_limit_buckets table so every instance
+// shares them. Each request is a single upsert, refilled with the database clock.
+type PostgresLimiter struct {
+	DB *gorm.DB
+}
+
+func NewPostgresLimiter(db *gorm.DB) *PostgresLimiter {
+	return &PostgresLimiter{DB: db}
+}
+
+const takeSQL = `
+INSERT INTO rate_limit_buckets (key, tokens, allowed, updated_at)
+VALUES (@key, @limit - 1, true, now())
+ON CONFLICT (key) DO UPDATE SET
+	allowed = LEAST(@limit, rate_limit_buckets.tokens + EXTRACT(EPOCH FROM now() - rate_limit_buckets.updated_at) * @rate) >= 1,
+	tokens = LEAST(@limit, rate_limit_buckets.tokens + EXTRACT(EPOCH FROM now() - rate_limit_buckets.updated_at) * @rate)
+		- CASE WHEN LEAST(@limit, rate_limit_buckets.tokens + EXTRACT(EPOCH FROM now() - rate_limit_buckets.updated_at) * @rate) >= 1 THEN 1 ELSE 0 END,
+	updated_at = now()
+RETURNING tokens, allowed`
+
+func (l *PostgresLimiter) Take(ctx context.Context, policy Policy, key string) (Result, error) {
+	var row struct {
+		Tokens  float64
+		Allowed bool
+	}
+
+	err := l.DB.WithContext(ctx).Raw(takeSQL, map[string]interface{}{
+		"key":   key,
+		"limit": float64(policy.Limit),
+		"rate":  policy.rate(),
+	}).Scan(&row).Error
+	if err != nil {
+		return Result{}, err
+	}
+
+	return result(policy, row.Tokens, row.Allowed), nil
+}
@@ -0,0 +1,117 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Policy is a token bucket holding up to Limit requests that refills completely
+// over Window
+type Policy struct {
+	Name       string
+	Limit      int
+	Window     time.Duration
+	WritesOnly bool // Leave GET, HEAD and OPTIONS requests alone
+}
+
+// Enabled reports whether the policy limits anything, "off" policies have no limit
+func (p Policy) Enabled() bool {
+	return p.Limit > 0 && p.Window > 0
+}
+
+// rate is the number of tokens added back per second
+func (p Policy) rate() float64 {
+	return float64(p.Limit) / p.Window.Seconds()
+}
+
+// Result describes the bucket after a request took, or failed to take, a token
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration // Until the next token, zero when allowed
+	Reset      time.Duration // Until the bucket is full again
+}
+
+// Limiter keeps one token bucket per key
+type Limiter interface {
+	Take(ctx context.Context, policy Policy, key string) (Result, error)
+}
+
+// NewFromEnv builds the limiter selected by RATE_LIMIT_BACKEND, "memory" (default)
+// for a single instance or "postgres" to share buckets between instances
+func NewFromEnv(db *gorm.DB) Limiter {
+	switch strings.ToLower(os.Getenv("RATE_LIMIT_BACKEND")) {
+	case "", "memory":
+		return NewMemoryLimiter()
+	case "postgres":
+		return NewPostgresLimiter(db)
+	default:
+		log.Fatalf("Unknown RATE_LIMIT_BACKEND %q", os.Getenv("RATE_LIMIT_BACKEND"))
+		return nil
+	}
+}
+
+// PolicyFromEnv reads a policy written as "<limit>/<window>", such as "10/1m", from
+// the given variable. "off" disables the policy and anything invalid falls back
+// to the defaults.
+func PolicyFromEnv(name, key string, limit int, window time.Duration) Policy {
+	policy := Policy{Name: name, Limit: limit, Window: window}
+
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return policy
+	}
+	if strings.EqualFold(value, "off") {
+		policy.Limit = 0
+		return policy
+	}
+
+	count, duration, ok := strings.Cut(value, "/")
+	parsedLimit, err := strconv.Atoi(count)
+	if !ok || err != nil || parsedLimit <= 0 {
+		log.Printf("Invalid %s %q, using %d/%s", key, value, limit, window)
+		return policy
+	}
+	parsedWindow, err := time.ParseDuration(duration)
+	if err != nil || parsedWindow <= 0 {
+		log.Printf("Invalid %s %q, using %d/%s", key, value, limit, window)
+		return policy
+	}
+
+	policy.Limit = parsedLimit
+	policy.Window = parsedWindow
+	return policy
+}
+
+// String formats the policy for the RateLimit-Policy header
+func (p Policy) String() string {
+	return fmt.Sprintf("%d;w=%d", p.Limit, int(math.Ceil(p.Window.Seconds())))
+}
+
+// result derives the response details from the tokens left in a bucket
+func result(policy Policy, tokens float64, allowed bool) Result {
+	res := Result{
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     secondsToDuration((float64(policy.Limit) - tokens) / policy.rate()),
+	}
+	if !allowed {
+		res.RetryAfter = secondsToDuration((1 - tokens) / policy.rate())
+	}
+	return res
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,166 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/farhapartex/ainventory/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// takeAll takes n tokens and returns every result
+func takeAll(t *testing.T, limiter Limiter, policy Policy, key string, n int) []Result {
+	t.Helper()
+
+	results := make([]Result, 0, n)
+	for i := 0; i < n; i++ {
+		res, err := limiter.Take(context.Background(), policy, key)
+		if err != nil {
+			t.Fatalf("take %d: %v", i, err)
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// testLimiterBucket runs the behaviour every backend shares
+func testLimiterBucket(t *testing.T, limiter Limiter, prefix string) {
+	policy := Policy{Name: "test", Limit: 3, Window: time.Hour}
+
+	results := takeAll(t, limiter, policy, prefix+"a", 4)
+	for i, res := range results[:3] {
+		if !res.Allowed {
+			t.Fatalf("request %d was denied", i)
+		}
+		if res.Remaining != 2-i {
+			t.Errorf("request %d remaining = %d, want %d", i, res.Remaining, 2-i)
+		}
+		if res.RetryAfter != 0 {
+			t.Errorf("request %d retry after = %s, want 0", i, res.RetryAfter)
+		}
+	}
+
+	denied := results[3]
+	if denied.Allowed {
+		t.Fatal("request over the limit was allowed")
+	}
+	if denied.Remaining != 0 {
+		t.Errorf("denied remaining = %d, want 0", denied.Remaining)
+	}
+	// One token comes back every 20 minutes
+	if denied.RetryAfter <= 19*time.Minute || denied.RetryAfter > 20*time.Minute {
+		t.Errorf("denied retry after = %s, want about 20m", denied.RetryAfter)
+	}
+	if denied.Reset <= 59*time.Minute || denied.Reset > time.Hour {
+		t.Errorf("denied reset = %s, want about 1h", denied.Reset)
+	}
+
+	other := takeAll(t, limiter, policy, prefix+"b", 1)[0]
+	if !other.Allowed || other.Remaining != 2 {
+		t.Errorf("another key shares the bucket: %+v", other)
+	}
+}
+
+func TestMemoryLimiter(t *testing.T) {
+	testLimiterBucket(t, NewMemoryLimiter(), "")
+}
+
+func TestMemoryLimiterRefills(t *testing.T) {
+	limiter := NewMemoryLimiter()
+	policy := Policy{Name: "test", Limit: 2, Window: 100 * time.Millisecond}
+
+	results := takeAll(t, limiter, policy, "client", 3)
+	if results[2].Allowed {
+		t.Fatal("request over the limit was allowed")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if res := takeAll(t, limiter, policy, "client", 1)[0]; !res.Allowed {
+		t.Errorf("bucket did not refill: %+v", res)
+	}
+}
+
+func TestMemoryLimiterSweepsFullBuckets(t *testing.T) {
+	limiter := NewMemoryLimiter()
+	policy := Policy{Name: "test", Limit: 2, Window: time.Minute}
+	takeAll(t, limiter, policy, "idle", 1)
+	takeAll(t, limiter, policy, "busy", 1)
+
+	now := time.Now()
+	limiter.buckets["idle"].updatedAt = now.Add(-2 * time.Minute)
+	limiter.lastSweep = now.Add(-sweepInterval)
+	limiter.sweep(now)
+
+	if _, ok := limiter.buckets["idle"]; ok {
+		t.Error("idle bucket was kept")
+	}
+	if _, ok := limiter.buckets["busy"]; !ok {
+		t.Error("busy bucket was dropped")
+	}
+}
+
+func TestPostgresLimiter(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("connecting to the test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.RateLimitBucket{}); err != nil {
+		t.Fatalf("migrating rate_limit_buckets: %v", err)
+	}
+
+	prefix := fmt.Sprintf("test:%d:", time.Now().UnixNano())
+	t.Cleanup(func() {
+		db.Where("key LIKE ?", prefix+"%").Delete(&models.RateLimitBucket{})
+	})
+
+	testLimiterBucket(t, NewPostgresLimiter(db), prefix)
+}
+
+func TestPolicyFromEnv(t *testing.T) {
+	tests := []struct {
+		value      string
+		wantLimit  int
+		wantWindow time.Duration
+	}{
+		{"", 10, time.Minute},
+		{"5/30s", 5, 30 * time.Second},
+		{" 100/1h ", 100, time.Hour},
+		{"off", 0, time.Minute},
+		{"OFF", 0, time.Minute},
+		{"5", 10, time.Minute},
+		{"0/1m", 10, time.Minute},
+		{"-1/1m", 10, time.Minute},
+		{"5/soon", 10, time.Minute},
+		{"5/-1m", 10, time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("RATE_LIMIT_TEST", tt.value)
+
+			policy := PolicyFromEnv("test", "RATE_LIMIT_TEST", 10, time.Minute)
+			if policy.Limit != tt.wantLimit || policy.Window != tt.wantWindow {
+				t.Errorf("policy = %d/%s, want %d/%s", policy.Limit, policy.Window, tt.wantLimit, tt.wantWindow)
+			}
+			if policy.Enabled() != (tt.wantLimit > 0) {
+				t.Errorf("enabled = %v", policy.Enabled())
+			}
+		})
+	}
+}
+
+func TestPolicyString(t *testing.T) {
+	policy := Policy{Limit: 10, Window: 1500 * time.Millisecond}
+	if got := policy.String(); got != "10;w=2" {
+		t.Errorf("String() = %q, want %q", got, "10;w=2")
+	}
+}
@@ -1,16 +1,27 @@
 package routes
 
 import (
+	"time"
+
 	"github.com/farhapartex/ainventory/controller"
 	"github.com/farhapartex/ainventory/middlewares"
+	"github.com/farhapartex/ainventory/ratelimit"
 	"github.com/farhapartex/ainventory/views"
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterRoute(r *gin.Engine, authController *controller.AuthController) {
+func RegisterRoute(r *gin.Engine, authController *controller.AuthController, limiter ratelimit.Limiter) {
+	authPolicy := ratelimit.PolicyFromEnv("auth", "RATE_LIMIT_AUTH", 10, time.Minute)
+	writePolicy := ratelimit.PolicyFromEnv("write", "RATE_LIMIT_WRITE", 120, time.Minute)
+	writePolicy.WritesOnly = true
+
+	r.GET("/.well-known/jwks.json", views.JWKSAPIView)
+
 	api := r.Group("/api/v1")
 	{
 		auth := api.Group("/auth")
+		auth.Use(middlewares.RateLimit(limiter, authPolicy, middlewares.RateLimitByIP))
+		auth.Use(middlewares.AuditMiddleware("/api/v1"))
 		{
 			auth.POST(("/signup/"), func(ctx *gin.Context) {
 				views.SignUpAPIView(ctx, authController)
@@ -18,12 +29,169 @@ func RegisterRoute(r *gin.Engine, authController *controller.AuthController) {
 			auth.POST(("/signin/"), func(ctx *gin.Context) {
 				views.SignInAPIView(ctx, authController)
 			})
+			auth.POST(("/refresh/"), func(ctx *gin.Context) {
+				views.RefreshTokenAPIView(ctx, authController)
+			})
+			auth.POST(("/verify-email/"), func(ctx *gin.Context) {
+				views.VerifyEmailAPIView(ctx, authController)
+			})
+			auth.POST(("/verify-email/resend/"), func(ctx *gin.Context) {
+				views.ResendVerificationEmailAPIView(ctx, authController)
+			})
+			auth.POST(("/password/forgot/"), func(ctx *gin.Context) {
+				views.ForgotPasswordAPIView(ctx, authController)
+			})
+			auth.POST(("/password/reset/"), func(ctx *gin.Context) {
+				views.ResetPasswordAPIView(ctx, authController)
+			})
+			auth.POST(("/2fa/verify/"), func(ctx *gin.Context) {
+				views.TwoFactorVerifyAPIView(ctx, authController)
+			})
+			auth.GET(("/oidc/login/"), func(ctx *gin.Context) {
+				views.OIDCLoginAPIView(ctx, authController)
+			})
+			auth.POST(("/oidc/callback/"), func(ctx *gin.Context) {
+				views.OIDCCallbackAPIView(ctx, authController)
+			})
 		}
 	}
 
 	protected := r.Group("/api/v1")
 	protected.Use(middlewares.AuthMiddleware())
+	protected.Use(middlewares.RateLimit(limiter, writePolicy, middlewares.RateLimitByClient))
+	protected.Use(middlewares.TenantMiddleware())
+	protected.Use(middlewares.AuditMiddleware("/api/v1"))
+	protected.Use(middlewares.PasswordPolicyMiddleware(
+		"/api/v1/auth/password/change/",
+		"/api/v1/auth/logout/",
+	))
 	{
+		// Routes under /auth and /user only act on the caller's own account and
+		// need no permission beyond being signed in
+		auth := protected.Group("/auth")
+		{
+			auth.POST(("/logout/"), func(ctx *gin.Context) {
+				views.LogoutAPIView(ctx, authController)
+			})
+			auth.POST(("/logout-all/"), func(ctx *gin.Context) {
+				views.LogoutAllAPIView(ctx, authController)
+			})
+			auth.POST(("/password/change/"), func(ctx *gin.Context) {
+				views.ChangePasswordAPIView(ctx, authController)
+			})
+		}
+
+		admin := protected.Group("/admin")
+		admin.Use(middlewares.RequirePermission("users.edit"))
+		// Administrators only manage users of their active organization
+		{
+			admin.POST(("/users/:id/logout-all/"), func(ctx *gin.Context) {
+				views.ForceLogoutUserAPIView(ctx, authController.WithContext(ctx.Request.Context()))
+			})
+			admin.POST(("/users/:id/unlock/"), func(ctx *gin.Context) {
+				views.UnlockUserAPIView(ctx, authController.WithContext(ctx.Request.Context()))
+			})
+			admin.POST(("/users/:id/2fa/reset/"), func(ctx *gin.Context) {
+				views.TwoFactorResetAPIView(ctx, authController.WithContext(ctx.Request.Context()))
+			})
+			admin.POST(("/users/:id/require-password-change/"), func(ctx *gin.Context) {
+				views.RequirePasswordChangeAPIView(ctx, authController.WithContext(ctx.Request.Context()))
+			})
+			admin.PUT(("/users/:id/role/"), middlewares.RequirePermission("roles.manage"), func(ctx *gin.Context) {
+				views.UserRoleAssignAPIView(ctx, authController.WithContext(ctx.Request.Context()))
+			})
+		}
+
+		users := protected.Group("/users")
+		{
+			users.GET(("/:id/permissions/"), middlewares.RequirePermission("users.view"), func(ctx *gin.Context) {
+				views.UserPermissionsExplainAPIView(ctx, authController.WithContext(ctx.Request.Context()))
+			})
+		}
+
+		auditLog := protected.Group("/audit-logs")
+		{
+			auditLog.GET(("/"), middlewares.RequirePermission("audit.view"), func(ctx *gin.Context) {
+				views.AuditLogListAPIView(ctx, authController.WithContext(ctx.Request.Context()))
+			})
+		}
+
+		// Roles, permissions and permission templates are shared by every
+		// organization, only superusers change roles and templates. Members hold a
+		// role per organization, it is assigned under /admin.
+		role := protected.Group("/roles")
+		role.Use(middlewares.RequirePermission("roles.manage"))
+		{
+			role.GET(("/"), func(ctx *gin.Context) {
+				views.RoleListAPIView(ctx, authController)
+			})
+			role.POST(("/"), func(ctx *gin.Context) {
+				views.RoleCreateAPIView(ctx, authController)
+			})
+			role.GET(("/:id"), func(ctx *gin.Context) {
+				views.RoleDetailAPIView(ctx, authController)
+			})
+			role.PATCH(("/:id"), func(ctx *gin.Context) {
+				views.RoleUpdateAPIView(ctx, authController)
+			})
+			role.DELETE(("/:id"), func(ctx *gin.Context) {
+				views.RoleDeleteAPIView(ctx, authController)
+			})
+			role.POST(("/:id/permissions/"), func(ctx *gin.Context) {
+				views.RolePermissionGrantAPIView(ctx, authController)
+			})
+			role.DELETE(("/:id/permissions/:permissionId"), func(ctx *gin.Context) {
+				views.RolePermissionRevokeAPIView(ctx, authController)
+			})
+		}
+
+		elevation := protected.Group("/elevations")
+		elevation.Use(middlewares.RequirePermission("roles.manage"))
+		{
+			elevation.GET(("/"), func(ctx *gin.Context) {
+				views.ElevationListAPIView(ctx, authController.WithContext(ctx.Request.Context()))
+			})
+			elevation.POST(("/:id/approve/"), func(ctx *gin.Context) {
+				views.ElevationApproveAPIView(ctx, authController.WithContext(ctx.Request.Context()))
+			})
+			elevation.POST(("/:id/deny/"), func(ctx *gin.Context) {
+				views.ElevationDenyAPIView(ctx, authController.WithContext(ctx.Request.Context()))
+			})
+		}
+
+		template := protected.Group("/permission-templates")
+		template.Use(middlewares.RequirePermission("roles.manage"))
+		{
+			template.GET(("/"), func(ctx *gin.Context) {
+				views.PermissionTemplateListAPIView(ctx, authController.WithContext(ctx.Request.Context()))
+			})
+			template.POST(("/"), func(ctx *gin.Context) {
+				views.PermissionTemplateCreateAPIView(ctx, authController.WithContext(ctx.Request.Context()))
+			})
+			template.GET(("/:id"), func(ctx *gin.Context) {
+				views.PermissionTemplateDetailAPIView(ctx, authController.WithContext(ctx.Request.Context()))
+			})
+			template.PATCH(("/:id"), func(ctx *gin.Context) {
+				views.PermissionTemplateUpdateAPIView(ctx, authController.WithContext(ctx.Request.Context()))
+			})
+			template.DELETE(("/:id"), func(ctx *gin.Context) {
+				views.PermissionTemplateDeleteAPIView(ctx, authController.WithContext(ctx.Request.Context()))
+			})
+			template.POST(("/:id/preview/"), func(ctx *gin.Context) {
+				views.PermissionTemplatePreviewAPIView(ctx, authController.WithContext(ctx.Request.Context()))
+			})
+			template.POST(("/:id/apply/"), func(ctx *gin.Context) {
+				views.PermissionTemplateApplyAPIView(ctx, authController.WithContext(ctx.Request.Context()))
+			})
+		}
+
+		permission := protected.Group("/permissions")
+		{
+			permission.GET(("/"), middlewares.RequirePermission("roles.manage"), func(ctx *gin.Context) {
+				views.PermissionListAPIView(ctx, authController)
+			})
+		}
+
 		user := protected.Group("/user")
 		{
 			user.GET(("/me/"), func(ctx *gin.Context) {
@@ -33,23 +201,116 @@ func RegisterRoute(r *gin.Engine, authController *controller.AuthController) {
 			user.POST(("/onboard/"), func(ctx *gin.Context) {
 				views.UserOnboardAPIView(ctx, authController)
 			})
+
+			user.GET(("/sessions/"), func(ctx *gin.Context) {
+				views.UserSessionListAPIView(ctx, authController)
+			})
+			user.DELETE(("/sessions/:id"), func(ctx *gin.Context) {
+				views.UserSessionDeleteAPIView(ctx, authController)
+			})
+
+			user.GET(("/elevations/"), func(ctx *gin.Context) {
+				views.UserElevationListAPIView(ctx, authController)
+			})
+			user.POST(("/elevations/"), func(ctx *gin.Context) {
+				views.ElevationCreateAPIView(ctx, authController.WithContext(ctx.Request.Context()))
+			})
+			user.DELETE(("/elevations/:id"), func(ctx *gin.Context) {
+				views.ElevationCancelAPIView(ctx, authController)
+			})
+
+			user.POST(("/invitations/accept/"), func(ctx *gin.Context) {
+				views.OrganizationInvitationAcceptAPIView(ctx, authController)
+			})
+
+			user.POST(("/2fa/setup/"), func(ctx *gin.Context) {
+				views.TwoFactorSetupAPIView(ctx, authController)
+			})
+			user.POST(("/2fa/confirm/"), func(ctx *gin.Context) {
+				views.TwoFactorConfirmAPIView(ctx, authController)
+			})
+			user.POST(("/2fa/disable/"), func(ctx *gin.Context) {
+				views.TwoFactorDisableAPIView(ctx, authController)
+			})
+		}
+		// Permissions are checked in the organization of the path, not the selected one
+		organization := protected.Group("/organization")
+		organization.Use(middlewares.PathOrganization("id"))
+		{
+			organization.PATCH(("/:id/password-policy/"), middlewares.RequirePermission("settings.edit"), func(ctx *gin.Context) {
+				views.PasswordPolicyUpdateAPIView(ctx, authController)
+			})
+			organization.GET(("/:id/settings/"), middlewares.RequirePermission("settings.view"), func(ctx *gin.Context) {
+				views.OrganizationSettingsAPIView(ctx, authController)
+			})
+			organization.PATCH(("/:id/settings/"), middlewares.RequirePermission("settings.edit"), func(ctx *gin.Context) {
+				views.OrganizationSettingsUpdateAPIView(ctx, authController)
+			})
+			organization.GET(("/:id/api-keys/"), middlewares.RequirePermission("settings.view"), func(ctx *gin.Context) {
+				views.APIKeyListAPIView(ctx, authController)
+			})
+			organization.POST(("/:id/api-keys/"), middlewares.RequirePermission("settings.edit"), func(ctx *gin.Context) {
+				views.APIKeyCreateAPIView(ctx, authController)
+			})
+			organization.DELETE(("/:id/api-keys/:keyId"), middlewares.RequirePermission("settings.edit"), func(ctx *gin.Context) {
+				views.APIKeyRevokeAPIView(ctx, authController)
+			})
+
+			// Exports are reserved to organization owners, purging to superusers
+			organization.POST(("/:id/exports/"), func(ctx *gin.Context) {
+				views.OrganizationExportCreateAPIView(ctx, authController)
+			})
+			organization.GET(("/:id/exports/"), func(ctx *gin.Context) {
+				views.OrganizationExportListAPIView(ctx, authController)
+			})
+			organization.GET(("/:id/exports/:exportId"), func(ctx *gin.Context) {
+				views.OrganizationExportDetailAPIView(ctx, authController)
+			})
+			organization.GET(("/:id/exports/:exportId/download"), func(ctx *gin.Context) {
+				views.OrganizationExportDownloadAPIView(ctx, authController)
+			})
+			organization.POST(("/:id/purge/"), func(ctx *gin.Context) {
+				views.OrganizationPurgeAPIView(ctx, authController)
+			})
+
+			// Members are managed through the organization role of the caller
+			organization.GET(("/:id/members/"), func(ctx *gin.Context) {
+				views.OrganizationMemberListAPIView(ctx, authController)
+			})
+			organization.PATCH(("/:id/members/:userId"), func(ctx *gin.Context) {
+				views.OrganizationMemberUpdateAPIView(ctx, authController)
+			})
+			organization.DELETE(("/:id/members/:userId"), func(ctx *gin.Context) {
+				views.OrganizationMemberRemoveAPIView(ctx, authController)
+			})
+			organization.GET(("/:id/invitations/"), func(ctx *gin.Context) {
+				views.OrganizationInvitationListAPIView(ctx, authController)
+			})
+			organization.POST(("/:id/invitations/"), func(ctx *gin.Context) {
+				views.OrganizationInvitationCreateAPIView(ctx, authController)
+			})
+			organization.DELETE(("/:id/invitations/:invitationId"), func(ctx *gin.Context) {
+				views.OrganizationInvitationRevokeAPIView(ctx, authController)
+			})
 		}
+		// Business data is scoped to the active organization of the request
 		product := protected.Group("/product")
+		product.Use(middlewares.RequireOrganization())
 		{
-			product.GET(("/categories/"), func(ctx *gin.Context) {
-				views.ProductCategoryListAPIView(ctx, authController)
+			product.GET(("/categories/"), middlewares.RequirePermission("products.view"), func(ctx *gin.Context) {
+				views.ProductCategoryListAPIView(ctx, authController.WithContext(ctx.Request.Context()))
 			})
-			product.POST(("/categories/"), func(ctx *gin.Context) {
-				views.ProductCategoryCreateAPIView(ctx, authController)
+			product.POST(("/categories/"), middlewares.RequirePermission("products.create"), func(ctx *gin.Context) {
+				views.ProductCategoryCreateAPIView(ctx, authController.WithContext(ctx.Request.Context()))
 			})
-			product.PATCH(("/categories/:id"), func(ctx *gin.Context) {
-				views.ProductCategoryUpdateAPIView(ctx, authController)
+			product.PATCH(("/categories/:id"), middlewares.RequirePermission("products.edit"), func(ctx *gin.Context) {
+				views.ProductCategoryUpdateAPIView(ctx, authController.WithContext(ctx.Request.Context()))
 			})
-			product.DELETE(("/categories/:id"), func(ctx *gin.Context) {
-				views.ProductCategoryDeleteAPIView(ctx, authController)
+			product.DELETE(("/categories/:id"), middlewares.RequirePermission("products.delete"), func(ctx *gin.Context) {
+				views.ProductCategoryDeleteAPIView(ctx, authController.WithContext(ctx.Request.Context()))
 			})
-			product.GET(("/suppliers/"), func(ctx *gin.Context) {
-				views.SupplierListAPIView(ctx, authController)
+			product.GET(("/suppliers/"), middlewares.RequirePermission("products.view"), func(ctx *gin.Context) {
+				views.SupplierListAPIView(ctx, authController.WithContext(ctx.Request.Context()))
 			})
 		}
 	}
@@ -0,0 +1,225 @@
+package seed
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/farhapartex/ainventory/models"
+	"gorm.io/gorm"
+)
+
+type demoProduct struct {
+	SKU          string
+	Name         string
+	Brand        string
+	CategoryCode string
+	SupplierCode string
+	Cost         float64
+	Price        float64
+	Quantity     int
+}
+
+type demoCustomer struct {
+	CustomerID string
+	FirstName  string
+	LastName   string
+	Email      string
+	Phone      string
+	Address    string
+	City       string
+	State      string
+	ZipCode    string
+}
+
+type demoOrderItem struct {
+	SKU      string
+	Quantity int
+}
+
+type demoOrder struct {
+	OrderNumber   string
+	CustomerEmail string
+	Status        string
+	PaymentStatus string
+	PaymentMethod string
+	DaysAgo       int
+	Items         []demoOrderItem
+}
+
+var demoProducts = []demoProduct{
+	{SKU: "DEMO-ELEC-001", Name: "Wireless Mouse", Brand: "Logi", CategoryCode: "ELEC", SupplierCode: "GEI", Cost: 12.50, Price: 24.99, Quantity: 150},
+	{SKU: "DEMO-ELEC-002", Name: "USB-C Charger 65W", Brand: "Anker", CategoryCode: "ELEC", SupplierCode: "GEI", Cost: 21.00, Price: 39.99, Quantity: 8},
+	{SKU: "DEMO-ELEC-003", Name: "Mechanical Keyboard", Brand: "Keychron", CategoryCode: "ELEC", SupplierCode: "TSC", Cost: 55.00, Price: 99.00, Quantity: 40},
+	{SKU: "DEMO-CLOTH-001", Name: "Cotton T-Shirt", Brand: "Basics", CategoryCode: "CLOTH", SupplierCode: "FFL", Cost: 4.20, Price: 14.99, Quantity: 300},
+	{SKU: "DEMO-CLOTH-002", Name: "Rain Jacket", Brand: "Northway", CategoryCode: "CLOTH", SupplierCode: "FFL", Cost: 32.00, Price: 79.00, Quantity: 0},
+	{SKU: "DEMO-HOME-001", Name: "Ceramic Mug Set", Brand: "Hearth", CategoryCode: "HOME", SupplierCode: "TSC", Cost: 9.80, Price: 22.50, Quantity: 75},
+}
+
+var demoCustomers = []demoCustomer{
+	{CustomerID: "DEMO-C001", FirstName: "Alice", LastName: "Walker", Email: "alice.walker@example.com", Phone: "+1-555-0101", Address: "12 Market Street", City: "Springfield", State: "IL", ZipCode: "62701"},
+	{CustomerID: "DEMO-C002", FirstName: "Bob", LastName: "Nguyen", Email: "bob.nguyen@example.com", Phone: "+1-555-0102", Address: "48 Lake Avenue", City: "Madison", State: "WI", ZipCode: "53703"},
+	{CustomerID: "DEMO-C003", FirstName: "Carmen", LastName: "Diaz", Email: "carmen.diaz@example.com", Phone: "+1-555-0103", Address: "7 Hill Road", City: "Austin", State: "TX", ZipCode: "73301"},
+}
+
+var demoOrders = []demoOrder{
+	{
+		OrderNumber: "DEMO-0001", CustomerEmail: "alice.walker@example.com", Status: "delivered", PaymentStatus: "paid", PaymentMethod: "credit_card", DaysAgo: 20,
+		Items: []demoOrderItem{{SKU: "DEMO-ELEC-001", Quantity: 2}, {SKU: "DEMO-ELEC-003", Quantity: 1}},
+	},
+	{
+		OrderNumber: "DEMO-0002", CustomerEmail: "bob.nguyen@example.com", Status: "processing", PaymentStatus: "paid", PaymentMethod: "paypal", DaysAgo: 3,
+		Items: []demoOrderItem{{SKU: "DEMO-CLOTH-001", Quantity: 5}},
+	},
+	{
+		OrderNumber: "DEMO-0003", CustomerEmail: "carmen.diaz@example.com", Status: "pending", PaymentStatus: "pending", PaymentMethod: "bank_transfer", DaysAgo: 0,
+		Items: []demoOrderItem{{SKU: "DEMO-HOME-001", Quantity: 2}, {SKU: "DEMO-ELEC-002", Quantity: 1}},
+	},
+}
+
+// seedDemo writes sample products, customers and orders for local development.
+// Rows that already exist are left as they are.
+func seedDemo(tx *gorm.DB, admin *models.User) error {
+	products := map[string]models.Product{}
+	for _, demo := range demoProducts {
+		product, err := seedDemoProduct(tx, admin, demo)
+		if err != nil {
+			return err
+		}
+		products[product.SKU] = *product
+	}
+
+	customers := map[string]models.Customer{}
+	for _, demo := range demoCustomers {
+		customer, err := seedDemoCustomer(tx, admin, demo)
+		if err != nil {
+			return err
+		}
+		customers[customer.Email] = *customer
+	}
+
+	for _, demo := range demoOrders {
+		if err := seedDemoOrder(tx, admin, demo, customers, products); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Seeded %d demo products, %d customers and %d orders", len(demoProducts), len(demoCustomers), len(demoOrders))
+	return nil
+}
+
+func seedDemoProduct(tx *gorm.DB, admin *models.User, demo demoProduct) (*models.Product, error) {
+	var product models.Product
+	if tx.Unscoped().Where("sku = ?", demo.SKU).First(&product).RowsAffected > 0 {
+		return &product, nil
+	}
+
+	var category models.ProductCategory
+	if err := tx.Where("code = ?", demo.CategoryCode).First(&category).Error; err != nil {
+		return nil, fmt.Errorf("demo product %s: category %s: %w", demo.SKU, demo.CategoryCode, err)
+	}
+
+	var supplier models.Supplier
+	if err := tx.Where("code = ?", demo.SupplierCode).First(&supplier).Error; err != nil {
+		return nil, fmt.Errorf("demo product %s: supplier %s: %w", demo.SKU, demo.SupplierCode, err)
+	}
+
+	product = models.Product{
+		Name:          demo.Name,
+		SKU:           demo.SKU,
+		Description:   demo.Name + " from the demo catalogue",
+		CategoryID:    category.ID,
+		Brand:         demo.Brand,
+		Cost:          demo.Cost,
+		Price:         demo.Price,
+		Quantity:      demo.Quantity,
+		TrackQuantity: true,
+		Status:        "active",
+		SupplierID:    supplier.ID,
+		CreatedBy:     admin.ID,
+	}
+	if err := tx.Create(&product).Error; err != nil {
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+func seedDemoCustomer(tx *gorm.DB, admin *models.User, demo demoCustomer) (*models.Customer, error) {
+	var customer models.Customer
+	if tx.Unscoped().Where("email = ?", demo.Email).First(&customer).RowsAffected > 0 {
+		return &customer, nil
+	}
+
+	customer = models.Customer{
+		CustomerID: demo.CustomerID,
+		FirstName:  demo.FirstName,
+		LastName:   demo.LastName,
+		Email:      demo.Email,
+		Phone:      demo.Phone,
+		Address:    demo.Address,
+		City:       demo.City,
+		State:      demo.State,
+		ZipCode:    demo.ZipCode,
+		Country:    "United States",
+		Source:     "demo",
+		CreatedBy:  admin.ID,
+	}
+	if err := tx.Create(&customer).Error; err != nil {
+		return nil, err
+	}
+
+	return &customer, nil
+}
+
+func seedDemoOrder(tx *gorm.DB, admin *models.User, demo demoOrder, customers map[string]models.Customer, products map[string]models.Product) error {
+	var count int64
+	tx.Unscoped().Model(&models.Order{}).Where("order_number = ?", demo.OrderNumber).Count(&count)
+	if count > 0 {
+		return nil
+	}
+
+	customer := customers[demo.CustomerEmail]
+	order := models.Order{
+		// Fixed ids keep reruns idempotent and avoid the time based generators
+		OrderID:         demo.OrderNumber,
+		OrderNumber:     demo.OrderNumber,
+		CustomerID:      customer.ID,
+		Status:          demo.Status,
+		OrderDate:       time.Now().AddDate(0, 0, -demo.DaysAgo),
+		ShippingName:    customer.GetFullName(),
+		ShippingEmail:   customer.Email,
+		ShippingPhone:   customer.Phone,
+		ShippingAddress: customer.Address,
+		ShippingCity:    customer.City,
+		ShippingState:   customer.State,
+		ShippingZip:     customer.ZipCode,
+		ShippingCountry: customer.Country,
+		PaymentStatus:   demo.PaymentStatus,
+		PaymentMethod:   demo.PaymentMethod,
+		CreatedBy:       admin.ID,
+	}
+	if err := tx.Create(&order).Error; err != nil {
+		return err
+	}
+
+	// OrderItem hooks compute the line and order totals
+	for _, demoItem := range demo.Items {
+		product := products[demoItem.SKU]
+		cost := product.Cost
+		item := models.OrderItem{
+			OrderID:     order.ID,
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			ProductSKU:  product.SKU,
+			Quantity:    demoItem.Quantity,
+			UnitPrice:   product.Price,
+			UnitCost:    &cost,
+		}
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
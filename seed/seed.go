@@ -0,0 +1,350 @@
+package seed
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/tenant"
+	"github.com/farhapartex/ainventory/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Options struct {
+	AdminEmail    string
+	AdminPassword string
+	Organization  string // Name of the organization owning the business data, created when missing
+	Demo          bool   // Also write demo products, customers and orders
+}
+
+// defaultRolePermissions maps the default roles to permission names. "*" matches
+// every permission and "module.*" every permission of one module.
+var defaultRolePermissions = map[string][]string{
+	"super_admin":     {"*"},
+	"admin":           {"dashboard.*", "products.*", "orders.*", "customers.*", "reports.*", "settings.*"},
+	"manager":         {"dashboard.*", "products.*", "orders.*", "customers.*", "reports.view"},
+	"warehouse_staff": {"dashboard.view", "products.view", "products.manage_inventory", "orders.view", "orders.process"},
+	"sales_rep":       {"dashboard.view", "products.view", "orders.view", "orders.create", "orders.edit", "customers.view", "customers.create", "customers.edit"},
+}
+
+// Run writes the default reference data. It is safe to run repeatedly: rows are
+// matched by Name or Code, reference data is refreshed and everything an admin
+// may have customised since, like role levels or grants, is left alone.
+func Run(db *gorm.DB, opts Options) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		permissions, err := seedPermissions(tx)
+		if err != nil {
+			return err
+		}
+
+		admin, err := seedAdmin(tx, opts)
+		if err != nil {
+			return err
+		}
+
+		if err := seedRoles(tx, admin, permissions); err != nil {
+			return err
+		}
+
+		organization, err := seedOrganization(tx, admin, opts.Organization)
+		if err != nil {
+			return err
+		}
+
+		// Business data belongs to the organization, the tenant scope stamps every row
+		scoped := tx.WithContext(tenant.WithOrganization(tx.Statement.Context, organization.ID))
+
+		if err := seedDepartments(scoped, admin); err != nil {
+			return err
+		}
+
+		if err := seedCategories(scoped); err != nil {
+			return err
+		}
+
+		if err := seedSuppliers(scoped, admin); err != nil {
+			return err
+		}
+
+		if opts.Demo {
+			return seedDemo(scoped, admin)
+		}
+
+		return nil
+	})
+}
+
+func seedPermissions(tx *gorm.DB) ([]models.Permission, error) {
+	permissions := models.GetDefaultPermissions()
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"display_name", "description", "module", "action", "resource", "updated_at"}),
+	}).Create(&permissions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	modules := []models.PermissionModule{}
+	seen := map[string]bool{}
+	for _, permission := range permissions {
+		if seen[permission.Module] {
+			continue
+		}
+		seen[permission.Module] = true
+		modules = append(modules, models.PermissionModule{
+			Name:        permission.Module,
+			DisplayName: strings.ToUpper(permission.Module[:1]) + permission.Module[1:],
+			SortOrder:   len(modules),
+		})
+	}
+
+	err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&modules).Error
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Seeded %d permissions in %d modules", len(permissions), len(modules))
+	return permissions, nil
+}
+
+// seedAdmin returns the superuser that owns the seeded rows, creating it on the
+// first run
+func seedAdmin(tx *gorm.DB, opts Options) (*models.User, error) {
+	var admin models.User
+	result := tx.Where("email = ?", opts.AdminEmail).First(&admin)
+	if result.RowsAffected > 0 {
+		if !admin.IsSuperuser {
+			return nil, errors.New("seed admin " + opts.AdminEmail + " exists but is not a superuser")
+		}
+		return &admin, nil
+	}
+
+	password := opts.AdminPassword
+	generated := password == ""
+	if generated {
+		var err error
+		password, err = utils.GenerateRandomString(12)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	admin = models.User{
+		FirstName:          "System",
+		LastName:           "Administrator",
+		Email:              opts.AdminEmail,
+		Password:           password,
+		IsSuperuser:        true,
+		Status:             "active",
+		EmailVerified:      true,
+		VerifiedAt:         &now,
+		MustChangePassword: generated,
+	}
+	if err := tx.Create(&admin).Error; err != nil {
+		return nil, err
+	}
+
+	if generated {
+		log.Printf("Created superuser %s with password %s, it must be changed on first sign in", admin.Email, password)
+	} else {
+		log.Printf("Created superuser %s", admin.Email)
+	}
+
+	return &admin, nil
+}
+
+// seedRoles creates the missing default roles with their permissions. Existing
+// roles keep their grants, except super_admin which always receives every
+// permission.
+func seedRoles(tx *gorm.DB, admin *models.User, permissions []models.Permission) error {
+	var defaultCount int64
+	tx.Model(&models.Role{}).Where("is_default = ?", true).Count(&defaultCount)
+
+	for _, role := range models.GetDefaultRoles() {
+		var existing models.Role
+		result := tx.Unscoped().Where("name = ?", role.Name).First(&existing)
+		created := result.RowsAffected == 0
+		if created {
+			role.CreatedBy = admin.ID
+			// An admin may already have picked another default role
+			role.IsDefault = role.IsDefault && defaultCount == 0
+			if err := tx.Create(&role).Error; err != nil {
+				return err
+			}
+			existing = role
+		}
+
+		if !created && role.Name != "super_admin" {
+			continue
+		}
+
+		grants := []models.RolePermission{}
+		for _, permission := range permissions {
+			if matchesAny(permission.Name, defaultRolePermissions[role.Name]) {
+				grants = append(grants, models.RolePermission{
+					RoleID:       existing.ID,
+					PermissionID: permission.ID,
+					GrantedBy:    admin.ID,
+				})
+			}
+		}
+		if len(grants) == 0 {
+			continue
+		}
+
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&grants).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Seeded %d roles", len(models.GetDefaultRoles()))
+	return nil
+}
+
+func seedDepartments(tx *gorm.DB, admin *models.User) error {
+	departmentRoles := models.GetDefaultDepartmentRoles()
+
+	for _, department := range models.GetDefaultDepartments() {
+		var existing models.Department
+		result := tx.Unscoped().Where("code = ?", department.Code).First(&existing)
+		if result.RowsAffected == 0 {
+			department.CreatedBy = admin.ID
+			if err := tx.Create(&department).Error; err != nil {
+				return err
+			}
+			existing = department
+		} else {
+			// UpdateColumns skips Department.AfterUpdate, the employee recount is not needed here
+			err := tx.Model(&existing).UpdateColumns(map[string]interface{}{
+				"name":        department.Name,
+				"description": department.Description,
+				"location":    department.Location,
+				"updated_at":  time.Now(),
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, role := range departmentRoles[department.Name] {
+			role.DepartmentID = existing.ID
+			role.IsActive = true
+
+			var existingRole models.DepartmentRole
+			result := tx.Where("department_id = ? AND name = ?", existing.ID, role.Name).First(&existingRole)
+			if result.RowsAffected == 0 {
+				if err := tx.Create(&role).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			err := tx.Model(&existingRole).Updates(map[string]interface{}{
+				"description":   role.Description,
+				"level":         role.Level,
+				"is_managerial": role.IsManagerial,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Printf("Seeded %d departments", len(models.GetDefaultDepartments()))
+	return nil
+}
+
+// seedOrganization finds the organization by name or creates it, and makes the
+// admin one of its owners holding the super_admin role
+func seedOrganization(tx *gorm.DB, admin *models.User, name string) (*models.Organization, error) {
+	var organization models.Organization
+	result := tx.Where("name = ?", name).First(&organization)
+	if result.RowsAffected == 0 {
+		organization = models.Organization{Name: name, OwnerID: admin.ID}
+		if err := tx.Create(&organization).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	membership := models.OrganizationMember{
+		OrganizationID: organization.ID,
+		UserID:         admin.ID,
+		Role:           models.OrganizationRoleOwner,
+	}
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"role": models.OrganizationRoleOwner}),
+	}).Create(&membership).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("organization_id = ? AND user_id = ?", organization.ID, admin.ID).First(&membership).Error; err != nil {
+		return nil, err
+	}
+	if membership.RoleID == nil {
+		var superAdmin models.Role
+		if err := tx.Where("name = ?", "super_admin").First(&superAdmin).Error; err == nil {
+			if err := membership.AssignRole(tx, &superAdmin.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	log.Printf("Seeded organization %q", organization.Name)
+	return &organization, nil
+}
+
+func seedCategories(tx *gorm.DB) error {
+	categories := models.GetDefaultCategories()
+	for i := range categories {
+		categories[i].SortOrder = i
+		categories[i].IsActive = true
+	}
+
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "description", "updated_at"}),
+	}).Create(&categories).Error
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Seeded %d product categories", len(categories))
+	return nil
+}
+
+func seedSuppliers(tx *gorm.DB, admin *models.User) error {
+	suppliers := models.GetDefaultSuppliers()
+	for i := range suppliers {
+		suppliers[i].CreatedBy = admin.ID
+	}
+
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "contact_person", "email", "phone", "country", "payment_terms", "updated_at"}),
+	}).Create(&suppliers).Error
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Seeded %d suppliers", len(suppliers))
+	return nil
+}
+
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == name {
+			return true
+		}
+		if module, ok := strings.CutSuffix(pattern, ".*"); ok && strings.HasPrefix(name, module+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+// Package tenant keeps the data of one organization invisible to every other.
+//
+// The models passed to Register are tenant scoped. Every query, update and
+// delete of such a model is limited to the organization carried by the statement
+// context, and every created row is stamped with it. Models that merely refer to
+// an organization, like its memberships, are not passed and stay unscoped. A
+// statement without an organization fails instead of reading or writing across
+// tenants. Background work that legitimately spans tenants opts out with
+// WithoutScope. Raw SQL and queries on a bare table name are never rewritten,
+// they filter on organization_id themselves.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Column is the column that ties a row to its organization
+const Column = "organization_id"
+
+// ErrNoOrganization is returned for statements on tenant scoped models whose
+// context has no active organization
+var ErrNoOrganization = errors.New("no active organization")
+
+// ErrCrossOrganization is returned when a statement tries to write a row of
+// another organization
+var ErrCrossOrganization = errors.New("row belongs to another organization")
+
+type organizationKey struct{}
+type unscopedKey struct{}
+
+// scope holds the model types whose rows belong to one organization
+type scope struct {
+	models map[reflect.Type]bool
+}
+
+// WithOrganization returns a context whose statements are limited to the organization
+func WithOrganization(ctx context.Context, organizationID uint) context.Context {
+	return context.WithValue(ctx, organizationKey{}, organizationID)
+}
+
+// OrganizationID returns the active organization of the context
+func OrganizationID(ctx context.Context) (uint, bool) {
+	id, ok := ctx.Value(organizationKey{}).(uint)
+	return id, ok && id != 0
+}
+
+// WithoutScope returns a context whose statements see every organization. Only
+// use it for system work such as migrations and maintenance jobs.
+func WithoutScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unscopedKey{}, true)
+}
+
+func isUnscoped(ctx context.Context) bool {
+	unscoped, _ := ctx.Value(unscopedKey{}).(bool)
+	return unscoped
+}
+
+// Register installs the scoping callbacks on the database for the given models,
+// each of which needs an OrganizationID field
+func Register(db *gorm.DB, models ...interface{}) error {
+	s := &scope{models: make(map[reflect.Type]bool, len(models))}
+	for _, model := range models {
+		statement := &gorm.Statement{DB: db}
+		if err := statement.Parse(model); err != nil {
+			return err
+		}
+		if statement.Schema.LookUpField(Column) == nil {
+			return fmt.Errorf("%s has no %s column", statement.Schema.Table, Column)
+		}
+		s.models[statement.Schema.ModelType] = true
+	}
+
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:before_create").Register("tenant:create", s.stampCreate); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:query", s.scopeStatement); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("tenant:row", s.scopeStatement); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:before_update").Register("tenant:update", s.scopeUpdate); err != nil {
+		return err
+	}
+	return callbacks.Delete().Before("gorm:before_delete").Register("tenant:delete", s.scopeStatement)
+}
+
+// tenantField returns the organization field of the statement model, nil for
+// models that are not tenant scoped or statements that opted out
+func (s *scope) tenantField(db *gorm.DB) *schema.Field {
+	if db.Error != nil || db.Statement.Schema == nil || isUnscoped(db.Statement.Context) {
+		return nil
+	}
+	if !s.models[db.Statement.Schema.ModelType] {
+		return nil
+	}
+	return db.Statement.Schema.LookUpField(Column)
+}
+
+func activeOrganization(db *gorm.DB) (uint, bool) {
+	id, ok := OrganizationID(db.Statement.Context)
+	if !ok {
+		db.AddError(fmt.Errorf("%w for %s", ErrNoOrganization, db.Statement.Schema.Table))
+	}
+	return id, ok
+}
+
+func (s *scope) scopeStatement(db *gorm.DB) {
+	field := s.tenantField(db)
+	if field == nil {
+		return
+	}
+	id, ok := activeOrganization(db)
+	if !ok {
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: id},
+	}})
+}
+
+func (s *scope) scopeUpdate(db *gorm.DB) {
+	field := s.tenantField(db)
+	if field == nil {
+		return
+	}
+	id, ok := activeOrganization(db)
+	if !ok {
+		return
+	}
+
+	// The row filter alone would still let an update move a row to another tenant
+	switch values := db.Statement.Dest.(type) {
+	case map[string]interface{}:
+		for _, key := range []string{field.DBName, field.Name} {
+			if value, set := values[key]; set && fmt.Sprint(value) != fmt.Sprint(id) {
+				db.AddError(ErrCrossOrganization)
+				return
+			}
+		}
+	default:
+		if err := checkStructs(db, field, reflect.ValueOf(db.Statement.Dest), id, false); err != nil {
+			db.AddError(err)
+			return
+		}
+	}
+
+	s.scopeStatement(db)
+}
+
+func (s *scope) stampCreate(db *gorm.DB) {
+	field := s.tenantField(db)
+	if field == nil {
+		return
+	}
+	id, ok := activeOrganization(db)
+	if !ok {
+		return
+	}
+
+	if err := checkStructs(db, field, db.Statement.ReflectValue, id, true); err != nil {
+		db.AddError(err)
+	}
+}
+
+// checkStructs walks a struct or slice of structs and refuses rows of another
+// organization. With stamp set, rows without an organization get the active one.
+func checkStructs(db *gorm.DB, field *schema.Field, value reflect.Value, id uint, stamp bool) error {
+	value = reflect.Indirect(value)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := checkStructs(db, field, value.Index(i), id, stamp); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		if value.Type() != db.Statement.Schema.ModelType {
+			return nil
+		}
+		current, zero := field.ValueOf(db.Statement.Context, value)
+		if zero {
+			if stamp {
+				return field.Set(db.Statement.Context, value, id)
+			}
+			return nil
+		}
+		if fmt.Sprint(current) != fmt.Sprint(id) {
+			return ErrCrossOrganization
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package tenant_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/farhapartex/ainventory/tenant"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// widget is tenant scoped, gadget only refers to an organization
+type widget struct {
+	ID             uint
+	OrganizationID uint
+	Name           string
+}
+
+type gadget struct {
+	ID             uint
+	OrganizationID uint
+	Name           string
+}
+
+// dryRunDB builds SQL without a database, statements report it in Statement.SQL
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("opening dry run database: %v", err)
+	}
+	if err := tenant.Register(db, &widget{}); err != nil {
+		t.Fatalf("registering scoping: %v", err)
+	}
+	return db
+}
+
+func TestRegisterRequiresOrganizationColumn(t *testing.T) {
+	type unowned struct {
+		ID   uint
+		Name string
+	}
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("opening dry run database: %v", err)
+	}
+	if err := tenant.Register(db, &unowned{}); err == nil {
+		t.Fatal("registering a model without organization_id succeeded")
+	}
+}
+
+func TestQueryScope(t *testing.T) {
+	db := dryRunDB(t)
+	inOrganization := tenant.WithOrganization(context.Background(), 7)
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		model    interface{}
+		scoped   bool
+		wantErr  error
+		wantArgs []interface{}
+	}{
+		{"scoped model in an organization", inOrganization, &[]widget{}, true, nil, []interface{}{uint(7)}},
+		{"scoped model without an organization", context.Background(), &[]widget{}, false, tenant.ErrNoOrganization, nil},
+		{"scoped model without scope", tenant.WithoutScope(context.Background()), &[]widget{}, false, nil, nil},
+		{"scoped model without scope in an organization", tenant.WithoutScope(inOrganization), &[]widget{}, false, nil, nil},
+		{"unscoped model in an organization", inOrganization, &[]gadget{}, false, nil, nil},
+		{"unscoped model without an organization", context.Background(), &[]gadget{}, false, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := db.WithContext(tt.ctx).Find(tt.model)
+			if !errors.Is(result.Error, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", result.Error, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			sql := result.Statement.SQL.String()
+			if scoped := strings.Contains(sql, `"organization_id" = $1`); scoped != tt.scoped {
+				t.Errorf("scoped = %v, want %v: %s", scoped, tt.scoped, sql)
+			}
+			if len(result.Statement.Vars) != len(tt.wantArgs) {
+				t.Fatalf("vars = %v, want %v", result.Statement.Vars, tt.wantArgs)
+			}
+			for i, arg := range tt.wantArgs {
+				if result.Statement.Vars[i] != arg {
+					t.Errorf("var %d = %v, want %v", i, result.Statement.Vars[i], arg)
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteScope(t *testing.T) {
+	db := dryRunDB(t)
+
+	result := db.WithContext(tenant.WithOrganization(context.Background(), 7)).Where("name = ?", "old").Delete(&widget{})
+	if result.Error != nil {
+		t.Fatalf("delete failed: %v", result.Error)
+	}
+	if sql := result.Statement.SQL.String(); !strings.Contains(sql, `"organization_id" = $`) {
+		t.Errorf("delete is not limited to the organization: %s", sql)
+	}
+
+	result = db.Where("name = ?", "old").Delete(&widget{})
+	if !errors.Is(result.Error, tenant.ErrNoOrganization) {
+		t.Errorf("delete without an organization: error = %v, want %v", result.Error, tenant.ErrNoOrganization)
+	}
+}
+
+func TestCreateStampsOrganization(t *testing.T) {
+	db := dryRunDB(t)
+	ctx := tenant.WithOrganization(context.Background(), 7)
+
+	tests := []struct {
+		name    string
+		rows    []widget
+		wantErr error
+	}{
+		{"rows without an organization", []widget{{Name: "a"}, {Name: "b"}}, nil},
+		{"rows of the active organization", []widget{{Name: "a", OrganizationID: 7}}, nil},
+		{"rows of another organization", []widget{{Name: "a"}, {Name: "b", OrganizationID: 8}}, tenant.ErrCrossOrganization},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.WithContext(ctx).Create(&tt.rows).Error
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			for i, row := range tt.rows {
+				if row.OrganizationID != 7 {
+					t.Errorf("row %d organization = %d, want 7", i, row.OrganizationID)
+				}
+			}
+		})
+	}
+
+	if err := db.WithContext(tenant.WithoutScope(context.Background())).Create(&widget{Name: "system"}).Error; err != nil {
+		t.Errorf("create without scope failed: %v", err)
+	}
+	if err := db.Create(&gadget{Name: "unscoped"}).Error; err != nil {
+		t.Errorf("create of an unscoped model failed: %v", err)
+	}
+}
+
+func TestUpdateRefusesCrossOrganizationWrites(t *testing.T) {
+	db := dryRunDB(t)
+	ctx := tenant.WithOrganization(context.Background(), 7)
+
+	tests := []struct {
+		name    string
+		update  func(tx *gorm.DB) *gorm.DB
+		wantErr error
+	}{
+		{"map update", func(tx *gorm.DB) *gorm.DB {
+			return tx.Model(&widget{ID: 1}).Updates(map[string]interface{}{"name": "renamed"})
+		}, nil},
+		{"map update moving the row", func(tx *gorm.DB) *gorm.DB {
+			return tx.Model(&widget{ID: 1}).Updates(map[string]interface{}{"organization_id": 8})
+		}, tenant.ErrCrossOrganization},
+		{"map update by field name moving the row", func(tx *gorm.DB) *gorm.DB {
+			return tx.Model(&widget{ID: 1}).Updates(map[string]interface{}{"OrganizationID": 8})
+		}, tenant.ErrCrossOrganization},
+		{"single column moving the row", func(tx *gorm.DB) *gorm.DB {
+			return tx.Model(&widget{ID: 1}).Update("organization_id", 8)
+		}, tenant.ErrCrossOrganization},
+		{"struct update", func(tx *gorm.DB) *gorm.DB {
+			return tx.Model(&widget{ID: 1}).Updates(widget{Name: "renamed"})
+		}, nil},
+		{"struct update moving the row", func(tx *gorm.DB) *gorm.DB {
+			return tx.Model(&widget{ID: 1}).Updates(widget{Name: "renamed", OrganizationID: 8})
+		}, tenant.ErrCrossOrganization},
+		{"save of another organization's row", func(tx *gorm.DB) *gorm.DB {
+			return tx.Save(&widget{ID: 1, Name: "renamed", OrganizationID: 8})
+		}, tenant.ErrCrossOrganization},
+		{"save of an own row", func(tx *gorm.DB) *gorm.DB {
+			return tx.Save(&widget{ID: 1, Name: "renamed", OrganizationID: 7})
+		}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.update(db.WithContext(ctx))
+			if !errors.Is(result.Error, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", result.Error, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if sql := result.Statement.SQL.String(); !strings.Contains(sql, `"organization_id" = $`) {
+				t.Errorf("update is not limited to the organization: %s", sql)
+			}
+		})
+	}
+
+	// Maintenance work may move rows between organizations
+	err := db.WithContext(tenant.WithoutScope(ctx)).Model(&widget{ID: 1}).Updates(map[string]interface{}{"organization_id": 8}).Error
+	if err != nil {
+		t.Errorf("update without scope failed: %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/farhapartex/ainventory/config"
 	"github.com/farhapartex/ainventory/models"
 	"github.com/gin-gonic/gin"
 )
@@ -22,6 +23,21 @@ func GetAuthenticatedUser(ctx *gin.Context) (*models.User, error) {
 	return &userModel, nil
 }
 
+// GetTokenClaims returns the claims and raw bearer token accepted by AuthMiddleware
+func GetTokenClaims(ctx *gin.Context) (*config.JWTClaims, string, error) {
+	claims, exists := ctx.Get("claims")
+	if !exists {
+		return nil, "", errors.New("token not found, need user login")
+	}
+
+	tokenClaims, ok := claims.(*config.JWTClaims)
+	if !ok {
+		return nil, "", errors.New("need user login")
+	}
+
+	return tokenClaims, ctx.GetString("token"), nil
+}
+
 func HandleAuthError(ctx *gin.Context, err error) {
 	ctx.JSON(http.StatusBadRequest, gin.H{
 		"error": err.Error(),
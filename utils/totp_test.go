@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the ASCII secret "12345678901234567890" of the RFC 4226 and RFC 6238
+// test vectors, base32 encoded
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// TestHOTPVectors checks the RFC 4226 Appendix D values
+func TestHOTPVectors(t *testing.T) {
+	want := []string{
+		"755224", "287082", "359152", "969429", "338314",
+		"254676", "287922", "162583", "399871", "520489",
+	}
+
+	key := []byte("12345678901234567890")
+	for counter, code := range want {
+		if got := hotp(key, int64(counter)); got != code {
+			t.Errorf("hotp(%d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+// TestValidateTOTPVectors checks the SHA1 values of RFC 6238 Appendix B. The RFC
+// lists 8 digit codes, the 6 digit code is their last 6 digits.
+func TestValidateTOTPVectors(t *testing.T) {
+	tests := []struct {
+		unix        int64
+		code        string
+		wantCounter int64
+	}{
+		{59, "287082", 0x1},
+		{1111111109, "081804", 0x23523EC},
+		{1111111111, "050471", 0x23523ED},
+		{1234567890, "005924", 0x273EF07},
+		{2000000000, "279037", 0x3F940AA},
+		{20000000000, "353130", 0x27BC86AA},
+	}
+
+	for _, tt := range tests {
+		counter, ok := ValidateTOTP(rfcSecret, tt.code, 0, time.Unix(tt.unix, 0))
+		if !ok {
+			t.Errorf("code %s at %d was rejected", tt.code, tt.unix)
+			continue
+		}
+		if counter != tt.wantCounter {
+			t.Errorf("code %s at %d matched step %#x, want %#x", tt.code, tt.unix, counter, tt.wantCounter)
+		}
+	}
+}
+
+func TestValidateTOTPWindow(t *testing.T) {
+	key := []byte("12345678901234567890")
+	at := time.Unix(1111111111, 0)
+	current := at.Unix() / totpPeriod
+
+	tests := []struct {
+		name        string
+		code        string
+		lastCounter int64
+		wantOK      bool
+		wantCounter int64
+	}{
+		{"current step", hotp(key, current), 0, true, current},
+		{"previous step", hotp(key, current-1), 0, true, current - 1},
+		{"next step", hotp(key, current+1), 0, true, current + 1},
+		{"two steps back", hotp(key, current-2), 0, false, 0},
+		{"two steps ahead", hotp(key, current+2), 0, false, 0},
+		{"replay of the used step", hotp(key, current), current, false, 0},
+		{"replay of an earlier step", hotp(key, current-1), current, false, 0},
+		{"step after the used one", hotp(key, current+1), current, true, current + 1},
+		{"older step after a newer was used", hotp(key, current-1), current - 1, false, 0},
+		{"surrounding whitespace", " " + hotp(key, current) + "\n", 0, true, current},
+		{"too short", hotp(key, current)[:5], 0, false, 0},
+		{"too long", hotp(key, current) + "0", 0, false, 0},
+		{"empty", "", 0, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter, ok := ValidateTOTP(rfcSecret, tt.code, tt.lastCounter, at)
+			if ok != tt.wantOK || counter != tt.wantCounter {
+				t.Errorf("ValidateTOTP = (%d, %v), want (%d, %v)", counter, ok, tt.wantCounter, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPSecret(t *testing.T) {
+	at := time.Unix(59, 0)
+
+	if _, ok := ValidateTOTP("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "287082", 0, at); !ok {
+		t.Error("lower case secret was rejected")
+	}
+	if _, ok := ValidateTOTP("not base32!", "287082", 0, at); ok {
+		t.Error("invalid secret was accepted")
+	}
+
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Fatalf("generated secret %q does not decode to 20 bytes: %v", secret, err)
+	}
+	if _, ok := ValidateTOTP(secret, hotp(key, at.Unix()/totpPeriod), 0, at); !ok {
+		t.Error("code of a generated secret was rejected")
+	}
+}
@@ -1,7 +1,11 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/farhapartex/ainventory/config"
@@ -10,31 +14,91 @@ import (
 )
 
 func GenerateToken(u models.User, TokenType string) (string, error) {
-	experiationTime := time.Now().Add(24 * time.Hour)
+	token, _, err := generateToken(u, TokenType, "", tokenTTL(TokenType))
+	return token, err
+}
+
+// GenerateAccessToken issues a bearer token bound to the session of the given token family
+func GenerateAccessToken(u models.User, familyID string) (string, error) {
+	token, _, err := generateToken(u, "access", familyID, config.AccessTokenTTL())
+	return token, err
+}
+
+// GenerateRefreshToken issues a refresh token belonging to the given token family
+func GenerateRefreshToken(u models.User, familyID string) (string, *config.JWTClaims, error) {
+	return generateToken(u, "refresh", familyID, config.RefreshTokenTTL())
+}
+
+// GenerateEmailVerificationToken issues the signed token embedded in verification links
+func GenerateEmailVerificationToken(u models.User) (string, error) {
+	token, _, err := generateToken(u, "email_verification", "", config.EmailVerificationTTL())
+	return token, err
+}
+
+// GenerateMFAToken issues the short lived token that proves the password step of a two factor sign in
+func GenerateMFAToken(u models.User) (string, error) {
+	token, _, err := generateToken(u, "mfa_pending", "", config.MFATokenTTL())
+	return token, err
+}
+
+func generateToken(u models.User, tokenType, familyID string, ttl time.Duration) (string, *config.JWTClaims, error) {
+	jti, err := GenerateRandomString(16)
+	if err != nil {
+		return "", nil, err
+	}
+
+	experiationTime := time.Now().Add(ttl)
 	claims := config.JWTClaims{
-		UserID:    u.ID,
-		Email:     u.Email,
-		TokenType: TokenType,
+		UserID:       u.ID,
+		Email:        u.Email,
+		TokenType:    tokenType,
+		FamilyID:     familyID,
+		TokenVersion: u.TokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(experiationTime),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
+	if tokenType == "access" {
+		claims.OrganizationID = u.DefaultOrganizationID(config.DB)
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	key := config.JWTKeys.Signing
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
 
-	return token.SignedString(config.JWTSecret)
+	signed, err := token.SignedString(key.Private)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return signed, &claims, nil
+}
 
+func tokenTTL(tokenType string) time.Duration {
+	if tokenType == "refresh" {
+		return config.RefreshTokenTTL()
+	}
+	return config.AccessTokenTTL()
 }
 
 func ValidateJWT(tokenString string) (*config.JWTClaims, error) {
 	claims := &config.JWTClaims{}
 
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := config.JWTKeys.Key(kid)
+		if !ok {
+			return nil, errors.New("Unknown signing key")
+		}
+
+		// The algorithm is tied to the key, never to what the token claims
+		if t.Method.Alg() != key.Method.Alg() {
 			return nil, errors.New("Unexected signing method")
 		}
 
-		return config.JWTSecret, nil
+		return key.Public, nil
 	})
 
 	if err != nil {
@@ -51,3 +115,45 @@ func ValidateJWT(tokenString string) (*config.JWTClaims, error) {
 
 	return claims, nil
 }
+
+// HashToken returns the hex encoded SHA256 hash used to store tokens at rest
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateRandomString returns a hex encoded string built from n random bytes
+func GenerateRandomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+const apiKeyPrefix = "aik"
+
+// GenerateAPIKey returns a new key of the form aik_<prefix>_<secret>. Only the
+// prefix is stored in clear text so the key can be found without its secret.
+func GenerateAPIKey() (key string, prefix string, err error) {
+	prefix, err = GenerateRandomString(6)
+	if err != nil {
+		return "", "", err
+	}
+
+	secret, err := GenerateRandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	return apiKeyPrefix + "_" + prefix + "_" + secret, prefix, nil
+}
+
+// ParseAPIKeyPrefix extracts the lookup prefix of a key produced by GenerateAPIKey
+func ParseAPIKeyPrefix(key string) (string, bool) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != apiKeyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
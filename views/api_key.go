@@ -0,0 +1,107 @@
+package views
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/farhapartex/ainventory/audit"
+	"github.com/farhapartex/ainventory/controller"
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func APIKeyCreateAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	organizationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid organization ID",
+		})
+		return
+	}
+
+	var req dto.APIKeyCreateRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	resp, err := ac.CreateAPIKey(user, uint(organizationID), req, ctx.ClientIP())
+	if err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	audit.SetAction(ctx, "api_keys.create")
+	audit.SetEntity(ctx, "api_keys", resp.ID)
+	audit.AddDetail(ctx, "organization_id", organizationID)
+
+	ctx.JSON(http.StatusCreated, resp)
+}
+
+func APIKeyListAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	organizationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid organization ID",
+		})
+		return
+	}
+
+	resp, err := ac.APIKeyList(user, uint(organizationID))
+	if err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": resp,
+	})
+}
+
+func APIKeyRevokeAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	organizationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid organization ID",
+		})
+		return
+	}
+
+	apiKeyID, err := strconv.ParseUint(ctx.Param("keyId"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid api key ID",
+		})
+		return
+	}
+
+	if err := ac.RevokeAPIKey(user, uint(organizationID), uint(apiKeyID), ctx.ClientIP()); err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "API key revoked successfully",
+	})
+}
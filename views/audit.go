@@ -0,0 +1,103 @@
+package views
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/farhapartex/ainventory/controller"
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func AuditLogListAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("pageSize", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	query := dto.AuditLogQueryDTO{
+		Page:       page,
+		PageSize:   pageSize,
+		Action:     ctx.Query("action"),
+		EntityType: ctx.Query("entity_type"),
+		EntityID:   ctx.Query("entity_id"),
+		Outcome:    ctx.Query("outcome"),
+		RequestID:  ctx.Query("request_id"),
+	}
+
+	if value := ctx.Query("user_id"); value != "" {
+		userID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			ctx.JSON(400, gin.H{
+				"error": "Invalid user ID",
+			})
+			return
+		}
+		id := uint(userID)
+		query.UserID = &id
+	}
+
+	if query.From, err = parseAuditTime(ctx.Query("from"), false); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid from date, use YYYY-MM-DD or RFC 3339",
+		})
+		return
+	}
+	if query.To, err = parseAuditTime(ctx.Query("to"), true); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid to date, use YYYY-MM-DD or RFC 3339",
+		})
+		return
+	}
+
+	resp, err := ac.AuditLogList(user, query)
+	if errors.Is(err, controller.ErrOrganizationAccess) {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// parseAuditTime accepts a timestamp or a date. A date used as upper bound
+// includes the whole day.
+func parseAuditTime(value string, endOfRange bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	if endOfRange {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
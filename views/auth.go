@@ -1,10 +1,14 @@
 package views
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
+	"github.com/farhapartex/ainventory/audit"
 	"github.com/farhapartex/ainventory/controller"
 	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -14,8 +18,10 @@ func SignUpAPIView(ctx *gin.Context, ac *controller.AuthController) {
 		ctx.JSON(400, gin.H{
 			"error": "Invalid input",
 		})
+		return
 	}
 
+	audit.SetSubject(ctx, req.Email)
 	response, err := ac.SignUp(req)
 	if err != nil {
 		ctx.JSON(400, gin.H{
@@ -33,9 +39,18 @@ func SignInAPIView(ctx *gin.Context, ac *controller.AuthController) {
 		ctx.JSON(400, gin.H{
 			"error": "Invalid input",
 		})
+		return
 	}
 
-	response, err := ac.SignIn(req)
+	audit.SetSubject(ctx, req.Email)
+	response, err := ac.SignIn(req, ctx.ClientIP(), ctx.Request.UserAgent())
+	if errors.Is(err, controller.ErrAccountLocked) {
+		ctx.JSON(http.StatusLocked, gin.H{
+			"error": err.Error(),
+			"code":  "ACCOUNT_LOCKED",
+		})
+		return
+	}
 	if err != nil {
 		ctx.JSON(400, gin.H{
 			"error": err.Error(),
@@ -45,3 +60,172 @@ func SignInAPIView(ctx *gin.Context, ac *controller.AuthController) {
 
 	ctx.JSON(http.StatusOK, response)
 }
+
+func RefreshTokenAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	var req dto.RefreshTokenRequestDTO
+	if err := ctx.ShouldBindJSON((&req)); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	response, err := ac.RefreshToken(req, ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func LogoutAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	claims, token, err := utils.GetTokenClaims(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	// The refresh token is optional, an empty body only revokes the access token
+	var req dto.LogoutRequestDTO
+	ctx.ShouldBindJSON(&req)
+
+	if err := ac.Logout(user, claims, token, req); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Logged out successfully",
+	})
+}
+
+func LogoutAllAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	if err := ac.LogoutAllSessions(user, "user_request"); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Logged out from all sessions",
+	})
+}
+
+func ForceLogoutUserAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	userID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	var req dto.ForceLogoutRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil && ctx.Request.ContentLength > 0 {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	if err := ac.ForceLogoutUser(user, uint(userID), req); err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "User logged out from all sessions",
+	})
+}
+
+func UnlockUserAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	userID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	if err := ac.UnlockUser(user, uint(userID), ctx.ClientIP()); err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Account unlocked successfully",
+	})
+}
+
+func VerifyEmailAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	var req dto.VerifyEmailRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	if err := ac.VerifyEmail(req); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Email verified successfully",
+	})
+}
+
+func ResendVerificationEmailAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	var req dto.ResendVerificationRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	audit.SetSubject(ctx, req.Email)
+	if err := ac.ResendVerificationEmail(req); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "If the account exists and is not verified, a verification email has been sent",
+	})
+}
@@ -0,0 +1,157 @@
+package views
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/farhapartex/ainventory/audit"
+	"github.com/farhapartex/ainventory/controller"
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func ElevationCreateAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	var req dto.ElevationCreateRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	resp, err := ac.RequestElevation(user, req, ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	audit.SetEntity(ctx, "elevations", resp.ID)
+
+	ctx.JSON(http.StatusCreated, resp)
+}
+
+func UserElevationListAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	resp, err := ac.UserElevationList(user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": resp,
+	})
+}
+
+func ElevationCancelAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	elevationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid elevation ID",
+		})
+		return
+	}
+
+	if err := ac.CancelElevation(user, uint(elevationID), ctx.ClientIP()); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Elevation request cancelled",
+	})
+}
+
+func ElevationListAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	resp, err := ac.ElevationList(user, ctx.Query("status"))
+	if errors.Is(err, controller.ErrOrganizationAccess) {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": resp,
+	})
+}
+
+func ElevationApproveAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	elevationReviewAPIView(ctx, ac, ac.ApproveElevation)
+}
+
+func ElevationDenyAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	elevationReviewAPIView(ctx, ac, ac.DenyElevation)
+}
+
+type elevationReviewFunc func(reviewer *models.User, elevationID uint, req dto.ElevationReviewRequestDTO, ipAddress string) (*dto.ElevationResponseDTO, error)
+
+func elevationReviewAPIView(ctx *gin.Context, ac *controller.AuthController, review elevationReviewFunc) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	elevationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid elevation ID",
+		})
+		return
+	}
+
+	var req dto.ElevationReviewRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil && ctx.Request.ContentLength > 0 {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	resp, err := review(user, uint(elevationID), req, ctx.ClientIP())
+	if err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,16 @@
+package views
+
+import (
+	"net/http"
+
+	"github.com/farhapartex/ainventory/config"
+	"github.com/farhapartex/ainventory/mapper"
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSAPIView publishes the public keys tokens are signed with so other services
+// can verify them without calling back
+func JWKSAPIView(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, mapper.JWTKeySetToJWKS(config.JWTKeys))
+}
@@ -0,0 +1,63 @@
+package views
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/farhapartex/ainventory/controller"
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/gin-gonic/gin"
+)
+
+func OIDCLoginAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	response, err := ac.OIDCLogin(ctx.Request.Context())
+	if errors.Is(err, controller.ErrOIDCDisabled) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"code":  "OIDC_DISABLED",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadGateway, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func OIDCCallbackAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	var req dto.OIDCCallbackRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	response, err := ac.OIDCCallback(ctx.Request.Context(), req, ctx.ClientIP(), ctx.Request.UserAgent())
+	if errors.Is(err, controller.ErrOIDCDisabled) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"code":  "OIDC_DISABLED",
+		})
+		return
+	}
+	if errors.Is(err, controller.ErrAccountLocked) {
+		ctx.JSON(http.StatusLocked, gin.H{
+			"error": err.Error(),
+			"code":  "ACCOUNT_LOCKED",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
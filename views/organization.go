@@ -0,0 +1,352 @@
+package views
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/farhapartex/ainventory/audit"
+	"github.com/farhapartex/ainventory/controller"
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func PasswordPolicyUpdateAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	organizationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid organization ID",
+		})
+		return
+	}
+
+	var req dto.PasswordPolicyRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	response, err := ac.UpdatePasswordPolicy(user, uint(organizationID), req)
+	if err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func OrganizationSettingsAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	organizationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid organization ID",
+		})
+		return
+	}
+
+	response, err := ac.OrganizationSettingsGet(user, uint(organizationID))
+	if err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func OrganizationSettingsUpdateAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	organizationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid organization ID",
+		})
+		return
+	}
+
+	var req dto.OrganizationSettingsRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	response, err := ac.UpdateOrganizationSettings(user, uint(organizationID), req, ctx.ClientIP())
+	if err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func OrganizationMemberListAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	organizationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid organization ID",
+		})
+		return
+	}
+
+	resp, err := ac.OrganizationMemberList(user, uint(organizationID))
+	if err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": resp,
+	})
+}
+
+func OrganizationMemberUpdateAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	organizationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid organization ID",
+		})
+		return
+	}
+
+	memberUserID, err := strconv.ParseUint(ctx.Param("userId"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	var req dto.OrganizationMemberRoleRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	resp, err := ac.UpdateOrganizationMember(user, uint(organizationID), uint(memberUserID), req, ctx.ClientIP())
+	if err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func OrganizationMemberRemoveAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	organizationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid organization ID",
+		})
+		return
+	}
+
+	memberUserID, err := strconv.ParseUint(ctx.Param("userId"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	if err := ac.RemoveOrganizationMember(user, uint(organizationID), uint(memberUserID), ctx.ClientIP()); err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Member removed successfully",
+	})
+}
+
+func OrganizationInvitationCreateAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	organizationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid organization ID",
+		})
+		return
+	}
+
+	var req dto.OrganizationInvitationRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	resp, err := ac.InviteOrganizationMember(user, uint(organizationID), req, ctx.ClientIP())
+	if err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	audit.SetEntity(ctx, "invitations", resp.ID)
+	ctx.JSON(http.StatusCreated, resp)
+}
+
+func OrganizationInvitationListAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	organizationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid organization ID",
+		})
+		return
+	}
+
+	resp, err := ac.OrganizationInvitationList(user, uint(organizationID))
+	if err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": resp,
+	})
+}
+
+func OrganizationInvitationRevokeAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	organizationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid organization ID",
+		})
+		return
+	}
+
+	invitationID, err := strconv.ParseUint(ctx.Param("invitationId"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid invitation ID",
+		})
+		return
+	}
+
+	if err := ac.RevokeOrganizationInvitation(user, uint(organizationID), uint(invitationID), ctx.ClientIP()); err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Invitation revoked successfully",
+	})
+}
+
+func OrganizationInvitationAcceptAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	var req dto.OrganizationInvitationAcceptRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	resp, err := ac.AcceptOrganizationInvitation(user, req, ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	audit.SetEntity(ctx, "organization", resp.ID)
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// writeOrganizationError answers 403 when the organization role is insufficient and
+// 409 when the change would leave the organization without an owner
+func writeOrganizationError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, controller.ErrOrganizationAccess):
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, controller.ErrLastOwner):
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+			"code":  "LAST_OWNER",
+		})
+	case errors.Is(err, controller.ErrExportInProgress):
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+			"code":  "EXPORT_IN_PROGRESS",
+		})
+	case errors.Is(err, controller.ErrExportNotReady):
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+			"code":  "EXPORT_NOT_READY",
+		})
+	case errors.Is(err, controller.ErrExportRequired):
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+			"code":  "EXPORT_REQUIRED",
+		})
+	default:
+		writeRoleAdminError(ctx, err)
+	}
+}
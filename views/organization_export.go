@@ -0,0 +1,162 @@
+package views
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/farhapartex/ainventory/audit"
+	"github.com/farhapartex/ainventory/controller"
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func OrganizationExportCreateAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	organizationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid organization ID",
+		})
+		return
+	}
+
+	resp, err := ac.CreateOrganizationExport(user, uint(organizationID), ctx.ClientIP())
+	if err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	audit.SetEntity(ctx, "exports", resp.ID)
+	ctx.JSON(http.StatusAccepted, resp)
+}
+
+func OrganizationExportListAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	organizationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid organization ID",
+		})
+		return
+	}
+
+	resp, err := ac.OrganizationExportList(user, uint(organizationID))
+	if err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": resp,
+	})
+}
+
+func OrganizationExportDetailAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	organizationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid organization ID",
+		})
+		return
+	}
+
+	exportID, err := strconv.ParseUint(ctx.Param("exportId"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid export ID",
+		})
+		return
+	}
+
+	resp, err := ac.OrganizationExportGet(user, uint(organizationID), uint(exportID))
+	if err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func OrganizationExportDownloadAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	organizationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid organization ID",
+		})
+		return
+	}
+
+	exportID, err := strconv.ParseUint(ctx.Param("exportId"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid export ID",
+		})
+		return
+	}
+
+	path, name, err := ac.OrganizationExportFile(user, uint(organizationID), uint(exportID), ctx.ClientIP())
+	if err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.FileAttachment(path, name)
+}
+
+func OrganizationPurgeAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	organizationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid organization ID",
+		})
+		return
+	}
+
+	var req dto.OrganizationPurgeRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	audit.SetAction(ctx, "organization.purge")
+	audit.SetEntity(ctx, "organization", organizationID)
+
+	resp, err := ac.PurgeOrganization(user, uint(organizationID), req, ctx.ClientIP())
+	if err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,106 @@
+package views
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/farhapartex/ainventory/audit"
+	"github.com/farhapartex/ainventory/controller"
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func ForgotPasswordAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	var req dto.ForgotPasswordRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	audit.SetSubject(ctx, req.Email)
+	if err := ac.ForgotPassword(req, ctx.ClientIP()); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "If the account exists, a password reset email has been sent",
+	})
+}
+
+func ResetPasswordAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	var req dto.ResetPasswordRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	if err := ac.ResetPassword(req, ctx.ClientIP()); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Password reset successfully, please sign in with your new password",
+	})
+}
+
+func ChangePasswordAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	var req dto.ChangePasswordRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	response, err := ac.ChangePassword(user, req, ctx.ClientIP(), ctx.Request.UserAgent())
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func RequirePasswordChangeAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	userID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	if err := ac.RequirePasswordChange(user, uint(userID), ctx.ClientIP()); err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "User must change password on next request",
+	})
+}
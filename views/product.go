@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/farhapartex/ainventory/audit"
 	"github.com/farhapartex/ainventory/controller"
 	"github.com/farhapartex/ainventory/dto"
 	"github.com/farhapartex/ainventory/utils"
@@ -54,6 +55,8 @@ func ProductCategoryCreateAPIView(ctx *gin.Context, ac *controller.AuthControlle
 		return
 	}
 
+	audit.SetEntity(ctx, "categories", response.ID)
+
 	ctx.JSON(http.StatusCreated, response)
 }
 
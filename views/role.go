@@ -0,0 +1,262 @@
+package views
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/farhapartex/ainventory/audit"
+	"github.com/farhapartex/ainventory/controller"
+	"github.com/farhapartex/ainventory/dto"
+	"github.com/farhapartex/ainventory/models"
+	"github.com/farhapartex/ainventory/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func RoleListAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	resp, err := ac.RoleList()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": resp,
+	})
+}
+
+func RoleDetailAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	roleID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid role ID",
+		})
+		return
+	}
+
+	resp, err := ac.RoleDetail(uint(roleID))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func RoleCreateAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	var request dto.RoleRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	response, err := ac.CreateRole(user, request)
+	if err != nil {
+		writeRoleAdminError(ctx, err)
+		return
+	}
+
+	audit.SetEntity(ctx, "roles", response.ID)
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+func RoleUpdateAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	roleID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid role ID",
+		})
+		return
+	}
+
+	var request dto.RoleRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	response, err := ac.UpdateRole(user, uint(roleID), request)
+	if err != nil {
+		writeRoleAdminError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func RoleDeleteAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	roleID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid role ID",
+		})
+		return
+	}
+
+	err = ac.DeleteRole(user, uint(roleID))
+	if errors.Is(err, models.ErrSystemRole) || errors.Is(err, models.ErrRoleInUse) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		writeRoleAdminError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Role deleted successfully",
+	})
+}
+
+func PermissionListAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	resp, err := ac.PermissionList()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": resp,
+	})
+}
+
+func RolePermissionGrantAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	roleID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid role ID",
+		})
+		return
+	}
+
+	var request dto.RolePermissionGrantRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	response, err := ac.GrantRolePermissions(user, uint(roleID), request)
+	if err != nil {
+		writeRoleAdminError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func RolePermissionRevokeAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	roleID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid role ID",
+		})
+		return
+	}
+
+	permissionID, err := strconv.ParseUint(ctx.Param("permissionId"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid permission ID",
+		})
+		return
+	}
+
+	if err := ac.RevokeRolePermission(user, uint(roleID), uint(permissionID)); err != nil {
+		writeRoleAdminError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Permission revoked successfully",
+	})
+}
+
+func UserRoleAssignAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	userID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	var request dto.UserRoleAssignRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid input",
+		})
+		return
+	}
+
+	if err := ac.AssignUserRole(user, uint(userID), request, ctx.ClientIP()); err != nil {
+		writeOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Role assigned successfully",
+	})
+}
+
+// writeRoleAdminError answers 403 for attempted privilege escalation and 400 otherwise
+func writeRoleAdminError(ctx *gin.Context, err error) {
+	status := http.StatusBadRequest
+	if errors.Is(err, controller.ErrPrivilegeEscalation) {
+		status = http.StatusForbidden
+	}
+
+	ctx.JSON(status, gin.H{
+		"error": err.Error(),
+	})
+}
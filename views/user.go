@@ -1,7 +1,9 @@
 package views
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/farhapartex/ainventory/controller"
 	"github.com/farhapartex/ainventory/dto"
@@ -53,3 +55,35 @@ func UserOnboardAPIView(ctx *gin.Context, ac *controller.AuthController) {
 
 	ctx.JSON(http.StatusOK, resp)
 }
+
+func UserPermissionsExplainAPIView(ctx *gin.Context, ac *controller.AuthController) {
+	user, err := utils.GetAuthenticatedUser(ctx)
+	if err != nil {
+		utils.HandleAuthError(ctx, err)
+		return
+	}
+
+	userID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(400, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	resp, err := ac.ExplainUserPermissions(user, uint(userID))
+	if errors.Is(err, controller.ErrOrganizationAccess) {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}